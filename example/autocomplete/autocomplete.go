@@ -0,0 +1,91 @@
+// Package autocomplete serves top-k word completions over HTTP. It is written
+// against the small Completer interface because the tries live in the
+// repository's root package main, which cannot be imported; VersionedTrie
+// satisfies Completer as is.
+package autocomplete
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultCompletions = 10
+	maxCompletions     = 100
+)
+
+// Completer returns up to k completions of prefix, best first. It must be
+// safe for concurrent use, since the handler calls it from every request.
+type Completer interface {
+	Complete(prefix string, k int) []string
+}
+
+// Handler serves GET /complete?q=<prefix>&k=<n>, answering with JSON like
+// {"query":"ca","completions":["cat","car"]}. Backed by a VersionedTrie, the
+// completions are the top k by insertion count, ties alphabetical, and reads
+// never take a lock, so the handler scales with concurrent requests while
+// words are inserted in the background.
+type Handler struct {
+	Completer Completer
+}
+
+// completeResponse is the JSON body returned by Handler.
+type completeResponse struct {
+	Query       string   `json:"query"`
+	Completions []string `json:"completions"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query().Get("q")
+	if !validQuery(q) {
+		http.Error(w, "q may only contain lowercase letters, apostrophes and hyphens", http.StatusBadRequest)
+		return
+	}
+
+	k := defaultCompletions
+	if raw := r.URL.Query().Get("k"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxCompletions {
+			http.Error(w, "k must be an integer between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		k = n
+	}
+
+	completions := h.Completer.Complete(q, k)
+	if completions == nil {
+		completions = []string{} // Encode as [] rather than null
+	}
+	body, err := json.Marshal(completeResponse{Query: q, Completions: completions})
+	if err != nil {
+		http.Error(w, "encoding response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(body, '\n')) // An error here means the client went away; nothing left to tell it
+}
+
+// validQuery reports whether q stays within the Trie alphabet: lowercase
+// letters, apostrophes and hyphens. Anything else would make the trie panic.
+func validQuery(q string) bool {
+	for i := 0; i < len(q); i++ {
+		if c := q[i]; (c < 'a' || c > 'z') && c != '\'' && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewMux returns a mux serving Handler at /complete.
+func NewMux(c Completer) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/complete", &Handler{Completer: c})
+	return mux
+}
@@ -0,0 +1,170 @@
+package autocomplete
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// sortedWords is a goroutine-safe Completer over a sorted word list, standing
+// in for VersionedTrie with the same design: writers are serialized by a
+// mutex and publish a new list with an atomic store, readers never block.
+// Completions come back in alphabetical order.
+type sortedWords struct {
+	mu    sync.Mutex // Serializes writers only
+	words atomic.Pointer[[]string]
+}
+
+func newSortedWords(words ...string) *sortedWords {
+	s := &sortedWords{}
+	s.words.Store(&[]string{})
+	for _, word := range words {
+		s.Insert(word)
+	}
+	return s
+}
+
+func (s *sortedWords) Insert(word string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	words := *s.words.Load()
+	if i, found := slices.BinarySearch(words, word); !found {
+		next := slices.Insert(slices.Clone(words), i, word)
+		s.words.Store(&next)
+	}
+}
+
+func (s *sortedWords) Delete(word string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	words := *s.words.Load()
+	if i, found := slices.BinarySearch(words, word); found {
+		next := slices.Delete(slices.Clone(words), i, i+1)
+		s.words.Store(&next)
+	}
+}
+
+func (s *sortedWords) Complete(prefix string, k int) []string {
+	words := *s.words.Load()
+	completions := []string{}
+	i, _ := slices.BinarySearch(words, prefix)
+	for ; i < len(words) && len(completions) < k && strings.HasPrefix(words[i], prefix); i++ {
+		completions = append(completions, words[i])
+	}
+	return completions
+}
+
+func TestHandler(t *testing.T) {
+	handler := NewMux(newSortedWords("car", "card", "care", "cat", "dog"))
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+		want   []string // Completions, checked when status is 200
+	}{
+		{"first k", http.MethodGet, "/complete?q=ca&k=2", http.StatusOK, []string{"car", "card"}},
+		{"default k", http.MethodGet, "/complete?q=ca", http.StatusOK, []string{"car", "card", "care", "cat"}},
+		{"k larger than matches", http.MethodGet, "/complete?q=do&k=100", http.StatusOK, []string{"dog"}},
+		{"no matches", http.MethodGet, "/complete?q=zz", http.StatusOK, []string{}},
+		{"empty query", http.MethodGet, "/complete?k=1", http.StatusOK, []string{"car"}},
+		{"apostrophe and hyphen", http.MethodGet, "/complete?q=o'-", http.StatusOK, []string{}},
+		{"invalid query", http.MethodGet, "/complete?q=Ca", http.StatusBadRequest, nil},
+		{"k not a number", http.MethodGet, "/complete?q=ca&k=x", http.StatusBadRequest, nil},
+		{"k zero", http.MethodGet, "/complete?q=ca&k=0", http.StatusBadRequest, nil},
+		{"k too large", http.MethodGet, "/complete?q=ca&k=101", http.StatusBadRequest, nil},
+		{"wrong method", http.MethodPost, "/complete?q=ca", http.StatusMethodNotAllowed, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			var resp completeResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+			}
+			if !slices.Equal(resp.Completions, tt.want) || resp.Completions == nil {
+				t.Errorf("completions = %q, want %q", resp.Completions, tt.want)
+			}
+		})
+	}
+}
+
+func ExampleNewMux() {
+	words := newSortedWords("car", "card", "care", "careful", "cat", "catalog", "dog")
+	server := httptest.NewServer(NewMux(words))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/complete?q=car&k=3")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Print(string(body))
+	// Output:
+	// {"query":"car","completions":["car","card","care"]}
+}
+
+// BenchmarkAutocompleteHandler drives the handler from parallel goroutines
+// while one writer keeps publishing new versions, which is the read path a
+// lock-free Completer such as VersionedTrie is meant to keep fast.
+func BenchmarkAutocompleteHandler(b *testing.B) {
+	var words []string
+	for _, c1 := range "abcdefghijklmnopqrstuvwxyz" {
+		for _, c2 := range "aeiou" {
+			for _, c3 := range "bcdlmnrst" {
+				words = append(words, string([]rune{c1, c2, c3}))
+			}
+		}
+	}
+	completer := newSortedWords(words...)
+	handler := NewMux(completer)
+
+	var stop atomic.Bool
+	done := make(chan struct{})
+	go func() { // Background writer
+		defer close(done)
+		for i := 0; !stop.Load(); i++ {
+			word := words[i%len(words)] + "s"
+			completer.Insert(word)
+			completer.Delete(word)
+		}
+	}()
+
+	prefixes := []string{"a", "ca", "bat", "mo", "th", "b", "don"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			target := fmt.Sprintf("/complete?q=%s&k=10", prefixes[i%len(prefixes)])
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+			if rec.Code != http.StatusOK {
+				b.Errorf("GET %s: status %d", target, rec.Code)
+			}
+			i++
+		}
+	})
+	b.StopTimer()
+	stop.Store(true)
+	<-done
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+func ExampleTrie_Search() {
+	trie := NewTrie()
+	for _, word := range []string{"cat", "car", "card", "apple", "app", "application"} {
+		trie.Insert(word)
+	}
+
+	fmt.Println(trie.Search("cat"))
+	fmt.Println(trie.Search("app"))
+	fmt.Println(trie.Search("ca")) // Only a prefix
+	fmt.Println(trie.Search("cow"))
+	fmt.Println(trie.StartsWith("ca"))
+	// Output:
+	// true
+	// true
+	// false
+	// false
+	// true
+}
+
+func ExampleTrie_CollectAllWordsStartingWith() {
+	trie := NewTrie()
+	for _, word := range []string{"cat", "car", "card", "apple", "app", "application"} {
+		trie.Insert(word)
+	}
+
+	fmt.Println(trie.CollectAllWordsStartingWith("a"))
+	fmt.Println(trie.CollectAllWordsStartingWith("car"))
+	fmt.Println(trie.CollectAllWordsStartingWith("z"))
+	// Output:
+	// [app apple application]
+	// [car card]
+	// []
+}
+
+func ExampleTrie_Delete() {
+	trie := NewTrie()
+	for _, word := range []string{"app", "apple", "application"} {
+		trie.Insert(word)
+	}
+
+	fmt.Println(trie.Delete("app"))
+	fmt.Println(trie.Search("app"))
+	fmt.Println(trie.Search("apple")) // Words below a deleted one are kept
+	fmt.Println(trie.Delete("nonexistent"))
+	fmt.Println(trie.Words())
+	// Output:
+	// true
+	// false
+	// true
+	// false
+	// [apple application]
+}
+
+func ExampleItemHeap() {
+	h := NewItemHeap()
+	h.Insert(5)
+	h.Insert(3)
+	h.Insert(8)
+	fmt.Println("Min:", h.GetMin())
+
+	h.Remove(3)
+	fmt.Println("Min after removing 3:", h.GetMin())
+
+	h.Insert(1)
+	h.Insert(9)
+	fmt.Println("Two smallest:", h.PeekN(2))
+	fmt.Println("Drained:", h.Drain())
+	// Output:
+	// Min: 3
+	// Min after removing 3: 5
+	// Two smallest: [1 5]
+	// Drained: [1 5 8 9]
+}
+
+func ExampleIntHeap() {
+	h := &IntHeap{2, 1, 5}
+	heap.Init(h)
+	heap.Push(h, 3)
+	fmt.Printf("minimum: %d\n", (*h)[0])
+	for h.Len() > 0 {
+		fmt.Printf("%d ", heap.Pop(h))
+	}
+	fmt.Println()
+	// Output:
+	// minimum: 1
+	// 1 2 3 5
+}
+
+func ExamplePriorityQueue() {
+	pq := NewPriorityQueue[int, string]()
+	pq.Push(3, "write docs")
+	review := pq.Push(5, "review PR")
+	pq.Push(1, "fix build")
+	pq.Push(3, "reply to issue")
+
+	pq.Update(review, 0) // Suddenly urgent
+
+	for pq.Len() > 0 {
+		priority, task, _ := pq.PopMin()
+		fmt.Printf("%d: %s\n", priority, task)
+	}
+	// Output:
+	// 0: review PR
+	// 1: fix build
+	// 3: write docs
+	// 3: reply to issue
+}
@@ -1,3 +1,5 @@
 module LeetCode
 
-go 1.23
+go 1.25.0
+
+require golang.org/x/text v0.37.0
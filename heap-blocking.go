@@ -0,0 +1,85 @@
+package main
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// BlockingPQ is a bounded, goroutine-safe priority queue built on ItemHeap.
+// Push blocks while the queue is full and PopMin blocks while it is empty,
+// which makes it usable like a channel that always yields the smallest value.
+type BlockingPQ struct {
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	h        *ItemHeap
+	capacity int
+	closed   bool
+}
+
+// NewBlockingPQ creates a BlockingPQ holding at most capacity values.
+// It panics if capacity is not positive.
+func NewBlockingPQ(capacity int) *BlockingPQ {
+	if capacity <= 0 {
+		panic("heap: BlockingPQ capacity must be positive")
+	}
+	pq := &BlockingPQ{
+		h:        NewItemHeap(),
+		capacity: capacity,
+	}
+	pq.notEmpty = sync.NewCond(&pq.mu)
+	pq.notFull = sync.NewCond(&pq.mu)
+	return pq
+}
+
+// Push adds x, blocking while the queue is at capacity.
+// It returns false if the queue is (or becomes) closed before x is added.
+func (pq *BlockingPQ) Push(x int) bool {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	for pq.h.Len() >= pq.capacity && !pq.closed {
+		pq.notFull.Wait()
+	}
+	if pq.closed {
+		return false
+	}
+	heap.Push(pq.h, x)
+	pq.notEmpty.Signal()
+	return true
+}
+
+// PopMin removes and returns the smallest value, blocking while the queue is empty.
+// After Close, PopMin keeps draining the remaining values and then returns ok=false.
+func (pq *BlockingPQ) PopMin() (int, bool) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	for pq.h.Len() == 0 && !pq.closed {
+		pq.notEmpty.Wait()
+	}
+	if pq.h.Len() == 0 {
+		return 0, false // Closed and drained
+	}
+	x := heap.Pop(pq.h).(int)
+	pq.notFull.Signal()
+	return x, true
+}
+
+// Len returns the number of values currently queued.
+func (pq *BlockingPQ) Len() int {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.h.Len()
+}
+
+// Close marks the queue as closed and wakes every blocked producer and consumer.
+// Pending producers return false; consumers drain what is left. Closing twice is a no-op.
+func (pq *BlockingPQ) Close() {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	pq.closed = true
+	pq.notEmpty.Broadcast()
+	pq.notFull.Broadcast()
+}
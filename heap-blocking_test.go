@@ -0,0 +1,111 @@
+package main
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestBlockingPQProducersConsumers runs several producers and consumers
+// through a small queue; run it with -race. Every pushed value must be popped
+// exactly once, and Close must let the consumers drain and exit.
+func TestBlockingPQProducersConsumers(t *testing.T) {
+	const producers, consumers, perProducer = 4, 4, 500
+	pq := NewBlockingPQ(8)
+
+	var produced sync.WaitGroup
+	for p := range producers {
+		produced.Add(1)
+		go func() {
+			defer produced.Done()
+			for i := range perProducer {
+				if !pq.Push(p*perProducer + i) {
+					t.Errorf("Push returned false before Close")
+					return
+				}
+			}
+		}()
+	}
+
+	results := make(chan []int, consumers)
+	for range consumers {
+		go func() {
+			var got []int
+			for {
+				x, ok := pq.PopMin()
+				if !ok {
+					results <- got
+					return
+				}
+				got = append(got, x)
+			}
+		}()
+	}
+
+	produced.Wait()
+	pq.Close()
+
+	var all []int
+	for range consumers {
+		all = append(all, <-results...)
+	}
+	slices.Sort(all)
+	if len(all) != producers*perProducer {
+		t.Fatalf("popped %d values, want %d", len(all), producers*perProducer)
+	}
+	for i, x := range all {
+		if x != i {
+			t.Fatalf("sorted popped values have %d at position %d; each value must be popped exactly once", x, i)
+		}
+	}
+}
+
+func TestBlockingPQPopsInOrder(t *testing.T) {
+	pq := NewBlockingPQ(10)
+	for _, x := range []int{5, 1, 4, 1, 3} {
+		pq.Push(x)
+	}
+	pq.Close()
+	var got []int
+	for {
+		x, ok := pq.PopMin()
+		if !ok {
+			break
+		}
+		got = append(got, x)
+	}
+	if !slices.Equal(got, []int{1, 1, 3, 4, 5}) {
+		t.Errorf("drained %v, want [1 1 3 4 5]", got)
+	}
+}
+
+func TestBlockingPQCloseUnblocksWaiters(t *testing.T) {
+	pq := NewBlockingPQ(1)
+	pq.Push(1)
+
+	pushed := make(chan bool)
+	go func() { pushed <- pq.Push(2) }() // Blocks: the queue is full
+
+	empty := NewBlockingPQ(1)
+	popped := make(chan bool)
+	go func() { _, ok := empty.PopMin(); popped <- ok }() // Blocks: the queue is empty
+
+	time.Sleep(10 * time.Millisecond) // Let both goroutines block
+	pq.Close()
+	empty.Close()
+	empty.Close() // Closing twice is a no-op
+
+	if <-pushed {
+		t.Error("blocked Push returned true after Close, want false")
+	}
+	if <-popped {
+		t.Error("blocked PopMin on an empty queue returned ok after Close, want false")
+	}
+	if x, ok := pq.PopMin(); !ok || x != 1 {
+		t.Errorf("PopMin() after Close = %d, %t, want the queued 1, true", x, ok)
+	}
+	if pq.Push(3) {
+		t.Error("Push after Close = true, want false")
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// BoundedKSmallest keeps the k smallest values seen in a stream. It is a
+// size-k max-heap: the root is the largest retained value, which is the one
+// a smaller newcomer displaces. Duplicates are retained like any other value.
+type BoundedKSmallest struct {
+	h       *boundedHeap
+	k       int
+	onEvict func(value int) // Optional, see OnEvict
+}
+
+// NewBoundedKSmallest creates a BoundedKSmallest retaining at most k values.
+// With k < 1 nothing is ever retained.
+func NewBoundedKSmallest(k int) *BoundedKSmallest {
+	return &BoundedKSmallest{
+		h: &boundedHeap{
+			items: make([]int, 0, max(k, 0)),
+			less:  func(a, b int) bool { return a > b },
+		},
+		k: k,
+	}
+}
+
+// NewBoundedHeap is NewBoundedKSmallest under the name used for fixed-capacity
+// heaps: Insert beyond capacity keeps a value only if it displaces the largest.
+func NewBoundedHeap(capacity int) *BoundedKSmallest {
+	return NewBoundedKSmallest(capacity)
+}
+
+// OnEvict registers fn to be called with each retained value that a smaller
+// Insert pushes out. Values rejected outright, because they are no smaller
+// than everything retained, are not reported. Pass nil to unregister.
+func (b *BoundedKSmallest) OnEvict(fn func(value int)) {
+	b.onEvict = fn
+}
+
+// Len returns the number of retained values, at most k.
+func (b *BoundedKSmallest) Len() int { return b.h.Len() }
+
+// Insert offers x and reports whether it was retained. Once k values are
+// held, x is retained only if it is smaller than the largest of them, which
+// is then evicted; a tie keeps the value already held.
+func (b *BoundedKSmallest) Insert(x int) bool {
+	if b.h.Len() < b.k {
+		heap.Push(b.h, x)
+		return true
+	}
+	if b.k < 1 || x >= b.h.items[0] {
+		return false
+	}
+	evicted := b.h.items[0]
+	b.h.items[0] = x
+	heap.Fix(b.h, 0)
+	if b.onEvict != nil {
+		b.onEvict(evicted)
+	}
+	return true
+}
+
+// Max returns the largest retained value, i.e. the bar a new value has to
+// get under once the set is full. ok is false when nothing is retained.
+func (b *BoundedKSmallest) Max() (int, bool) {
+	if b.h.Len() == 0 {
+		return 0, false
+	}
+	return b.h.items[0], true
+}
+
+// Values returns the retained values in ascending order.
+func (b *BoundedKSmallest) Values() []int {
+	values := slices.Clone(b.h.items)
+	slices.Sort(values)
+	return values
+}
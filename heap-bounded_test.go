@@ -0,0 +1,91 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBoundedKSmallestEvictions(t *testing.T) {
+	b := NewBoundedKSmallest(3)
+	var evicted []int
+	b.OnEvict(func(value int) { evicted = append(evicted, value) })
+
+	steps := []struct {
+		x         int
+		kept      bool
+		evicts    []int
+		wantAfter []int
+	}{
+		{5, true, nil, []int{5}},
+		{9, true, nil, []int{5, 9}},
+		{7, true, nil, []int{5, 7, 9}},
+		{8, true, []int{9}, []int{5, 7, 8}},
+		{10, false, nil, []int{5, 7, 8}}, // Above the bar: rejected, not evicted
+		{8, false, nil, []int{5, 7, 8}},  // A tie keeps the value already held
+		{1, true, []int{8}, []int{1, 5, 7}},
+		{5, true, []int{7}, []int{1, 5, 5}},
+	}
+	for _, step := range steps {
+		evicted = nil
+		if got := b.Insert(step.x); got != step.kept {
+			t.Errorf("Insert(%d) = %t, want %t", step.x, got, step.kept)
+		}
+		if !slices.Equal(evicted, step.evicts) {
+			t.Errorf("Insert(%d) evicted %v, want %v", step.x, evicted, step.evicts)
+		}
+		if got := b.Values(); !slices.Equal(got, step.wantAfter) {
+			t.Errorf("after Insert(%d): Values() = %v, want %v", step.x, got, step.wantAfter)
+		}
+	}
+}
+
+// TestBoundedKSmallestStream streams random values and checks that the
+// retained set is the k smallest and that retained plus evicted values
+// account for every accepted insert.
+func TestBoundedKSmallestStream(t *testing.T) {
+	rng := newTestRand(174)
+	for _, k := range []int{0, 1, 5, 50} {
+		b := NewBoundedKSmallest(k)
+		var evicted []int
+		b.OnEvict(func(value int) { evicted = append(evicted, value) })
+
+		stream := randomInts(rng, 500, 200)
+		accepted := []int{}
+		for _, x := range stream {
+			if b.Insert(x) {
+				accepted = append(accepted, x)
+			}
+		}
+
+		sorted := slices.Sorted(slices.Values(stream))
+		if got, want := b.Values(), sorted[:min(k, len(sorted))]; !slices.Equal(got, want) {
+			t.Errorf("k=%d: Values() = %v, want %v", k, got, want)
+		}
+		all := slices.Sorted(slices.Values(append(b.Values(), evicted...)))
+		if !slices.Equal(all, slices.Sorted(slices.Values(accepted))) {
+			t.Errorf("k=%d: retained and evicted values don't add up to the accepted inserts", k)
+		}
+		if got, ok := b.Max(); k > 0 && (!ok || got != sorted[k-1]) {
+			t.Errorf("k=%d: Max() = %d, %t; want %d", k, got, ok, sorted[k-1])
+		}
+	}
+}
+
+func TestNewBoundedHeapKeepsTenSmallest(t *testing.T) {
+	stream := randomInts(newTestRand(276), 1000, 1_000_000)
+	b := NewBoundedHeap(10)
+	for i, x := range stream {
+		bar, _ := b.Max()
+		want := i < 10 || x < bar // Fills up first, then only displaces the largest
+		if got := b.Insert(x); got != want {
+			t.Fatalf("insert %d: Insert(%d) with bar %d = %t, want %t", i, x, bar, got, want)
+		}
+		if b.Len() > 10 || cap(b.h.items) > 10 {
+			t.Fatalf("after %d inserts Len() = %d, cap = %d; want at most 10", i+1, b.Len(), cap(b.h.items))
+		}
+	}
+	want := slices.Sorted(slices.Values(stream))[:10]
+	if got := b.Values(); !slices.Equal(got, want) {
+		t.Errorf("Values() = %v, want the 10 smallest %v", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import "container/heap"
+
+// Heap is the generic form of ItemHeap: a heap of comparable values ordered
+// by a caller-supplied less, with an index map so any value can be removed in
+// O(log n). Unlike ItemHeap it holds each value at most once. The value for
+// which less reports true against every other value sits at the top, so the
+// same T can back a min- or a max-heap.
+//
+// *Heap[T] satisfies heap.Interface just like *ItemHeap. Values are stored and
+// returned by value; if T holds pointers, slices or maps, the caller must not
+// mutate what they point to in a way that changes the ordering or equality.
+type Heap[T comparable] struct {
+	items []T
+	index map[T]int // item -> index in heap
+	less  func(a, b T) bool
+}
+
+var _ heap.Interface = (*Heap[int])(nil)
+
+// NewHeap creates an empty Heap ordered by less.
+func NewHeap[T comparable](less func(a, b T) bool) *Heap[T] {
+	return &Heap[T]{
+		items: []T{},
+		index: make(map[T]int),
+		less:  less,
+	}
+}
+
+func (h *Heap[T]) Len() int           { return len(h.items) }
+func (h *Heap[T]) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h *Heap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.index[h.items[i]] = i
+	h.index[h.items[j]] = j
+}
+
+func (h *Heap[T]) Push(x any) {
+	item := x.(T)
+	h.index[item] = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *Heap[T]) Pop() any {
+	n := len(h.items)
+	item := h.items[n-1]
+	var zero T
+	h.items[n-1] = zero // Don't keep the popped value reachable
+	h.items = h.items[:n-1]
+	delete(h.index, item)
+	return item
+}
+
+// Insert adds x to the heap. Values are unique, so inserting one that is
+// already present does nothing.
+func (h *Heap[T]) Insert(x T) {
+	if _, ok := h.index[x]; ok {
+		return
+	}
+	heap.Push(h, x)
+}
+
+// GetMin returns the top value without removing it.
+// It panics on an empty heap; use Peek when the heap may be empty.
+func (h *Heap[T]) GetMin() T {
+	if len(h.items) == 0 {
+		panic("heap: GetMin called on empty Heap")
+	}
+	return h.items[0]
+}
+
+// Peek is the non-panicking GetMin: ok is false when the heap is empty.
+func (h *Heap[T]) Peek() (T, bool) {
+	if len(h.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return h.items[0], true
+}
+
+// PopMin removes and returns the top value. ok is false, and the heap is left
+// untouched, when it is empty.
+func (h *Heap[T]) PopMin() (T, bool) {
+	if len(h.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return heap.Pop(h).(T), true
+}
+
+// Remove deletes x from the heap, reporting whether it was present.
+func (h *Heap[T]) Remove(x T) bool {
+	i, ok := h.index[x]
+	if !ok {
+		return false
+	}
+	heap.Remove(h, i)
+	return true
+}
+
+// Contains reports whether x is in the heap.
+func (h *Heap[T]) Contains(x T) bool {
+	_, ok := h.index[x]
+	return ok
+}
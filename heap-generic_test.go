@@ -0,0 +1,103 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// checkGenericHeap fails t if h breaks the heap property or its index map
+// doesn't point at every item.
+func checkGenericHeap[T comparable](t *testing.T, h *Heap[T]) {
+	t.Helper()
+	if len(h.index) != len(h.items) {
+		t.Fatalf("index has %d entries for %d items", len(h.index), len(h.items))
+	}
+	for i, item := range h.items {
+		if h.index[item] != i {
+			t.Fatalf("index[%v] = %d, want %d", item, h.index[item], i)
+		}
+		if i > 0 && h.Less(i, (i-1)/2) {
+			t.Fatalf("item %v at %d sorts before its parent %v", item, i, h.items[(i-1)/2])
+		}
+	}
+}
+
+// drainHeap pops every value from h in order.
+func drainHeap[T comparable](h *Heap[T]) []T {
+	var out []T
+	for {
+		x, ok := h.PopMin()
+		if !ok {
+			return out
+		}
+		out = append(out, x)
+	}
+}
+
+func TestHeapStrings(t *testing.T) {
+	words := []string{"pear", "apple", "fig", "banana", "cherry", "date", "apple"}
+	minHeap := NewHeap(func(a, b string) bool { return a < b })
+	maxHeap := NewHeap(func(a, b string) bool { return a > b })
+	for _, word := range words {
+		minHeap.Insert(word)
+		maxHeap.Insert(word)
+		checkGenericHeap(t, minHeap)
+		checkGenericHeap(t, maxHeap)
+	}
+	if minHeap.Len() != 6 {
+		t.Errorf("Len() = %d, want 6 (the repeated apple is stored once)", minHeap.Len())
+	}
+	if got := minHeap.GetMin(); got != "apple" {
+		t.Errorf("min-heap GetMin() = %q, want apple", got)
+	}
+	if got := maxHeap.GetMin(); got != "pear" {
+		t.Errorf("max-heap GetMin() = %q, want pear", got)
+	}
+
+	if !minHeap.Remove("cherry") || minHeap.Remove("cherry") || minHeap.Contains("cherry") {
+		t.Error(`Remove("cherry") should succeed once and leave it absent`)
+	}
+	checkGenericHeap(t, minHeap)
+	if got, want := drainHeap(minHeap), []string{"apple", "banana", "date", "fig", "pear"}; !slices.Equal(got, want) {
+		t.Errorf("min-heap drained %q, want %q", got, want)
+	}
+	if got, want := drainHeap(maxHeap), []string{"pear", "fig", "date", "cherry", "banana", "apple"}; !slices.Equal(got, want) {
+		t.Errorf("max-heap drained %q, want %q", got, want)
+	}
+	if _, ok := minHeap.Peek(); ok {
+		t.Error("Peek() on a drained heap reported ok")
+	}
+}
+
+func TestHeapStructsByAge(t *testing.T) {
+	type person struct {
+		Name string
+		Age  int
+	}
+	h := NewHeap(func(a, b person) bool { return a.Age < b.Age })
+	people := []person{{"Ann", 41}, {"Bo", 7}, {"Cy", 63}, {"Di", 19}, {"Ed", 30}}
+	for _, p := range people {
+		h.Insert(p)
+	}
+	checkGenericHeap(t, h)
+
+	if got := h.GetMin(); got != (person{"Bo", 7}) {
+		t.Errorf("GetMin() = %+v, want Bo, 7", got)
+	}
+	// Equality is on the whole struct, so a different Name is a different value.
+	if h.Remove(person{"Bo", 8}) || h.Remove(person{"Bob", 7}) {
+		t.Error("Remove matched a person that was never inserted")
+	}
+	if !h.Remove(person{"Di", 19}) {
+		t.Error(`Remove({"Di", 19}) = false, want true`)
+	}
+	checkGenericHeap(t, h)
+
+	var ages []int
+	for _, p := range drainHeap(h) {
+		ages = append(ages, p.Age)
+	}
+	if want := []int{7, 30, 41, 63}; !slices.Equal(ages, want) {
+		t.Errorf("drained ages %v, want %v", ages, want)
+	}
+}
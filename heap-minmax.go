@@ -0,0 +1,158 @@
+package main
+
+// MinMaxHeap is a double-ended priority queue laid out as an interval heap:
+// node k owns the slots 2k (its low end) and 2k+1 (its high end), and every
+// node's interval contains the intervals of its children. The low ends thus
+// form a min-heap and the high ends a max-heap, giving O(1) Min and Max and
+// O(log n) Insert, PopMin and PopMax. Like ItemHeap it lives in a plain
+// slice, but it needs no index map, so duplicate values are fine.
+type MinMaxHeap struct {
+	items []int
+}
+
+func NewMinMaxHeap() *MinMaxHeap {
+	return &MinMaxHeap{items: []int{}}
+}
+
+// Len returns the number of values in the heap.
+func (h *MinMaxHeap) Len() int { return len(h.items) }
+
+// Min returns the smallest value. ok is false when the heap is empty.
+func (h *MinMaxHeap) Min() (int, bool) {
+	if len(h.items) == 0 {
+		return 0, false
+	}
+	return h.items[0], true
+}
+
+// Max returns the largest value. ok is false when the heap is empty.
+func (h *MinMaxHeap) Max() (int, bool) {
+	if len(h.items) == 0 {
+		return 0, false
+	}
+	return h.items[h.hi(0)], true
+}
+
+// hi returns the slot of node k's high end. The last node may hold a single
+// value, which then serves as both ends.
+func (h *MinMaxHeap) hi(k int) int {
+	return min(2*k+1, len(h.items)-1)
+}
+
+// Insert adds x to the heap.
+func (h *MinMaxHeap) Insert(x int) {
+	h.items = append(h.items, x)
+	k := (len(h.items) - 1) / 2
+	if lo, hi := 2*k, h.hi(k); h.items[lo] > h.items[hi] {
+		h.items[lo], h.items[hi] = h.items[hi], h.items[lo]
+	}
+	// Only one of these can move anything: x either undercuts the parent's
+	// low end, overshoots its high end, or already fits.
+	h.minUp(k)
+	h.maxUp(k)
+}
+
+// minUp moves node k's low end up while it is below its parent's.
+func (h *MinMaxHeap) minUp(k int) {
+	for k > 0 {
+		p := (k - 1) / 2
+		if h.items[2*k] >= h.items[2*p] {
+			return
+		}
+		h.items[2*k], h.items[2*p] = h.items[2*p], h.items[2*k]
+		k = p
+	}
+}
+
+// maxUp moves node k's high end up while it is above its parent's.
+func (h *MinMaxHeap) maxUp(k int) {
+	for k > 0 {
+		p := (k - 1) / 2
+		c, pp := h.hi(k), 2*p+1
+		if h.items[c] <= h.items[pp] {
+			return
+		}
+		h.items[c], h.items[pp] = h.items[pp], h.items[c]
+		k = p
+	}
+}
+
+// PopMin removes and returns the smallest value. ok is false, and the heap
+// is left untouched, when it is empty.
+func (h *MinMaxHeap) PopMin() (int, bool) {
+	n := len(h.items)
+	if n == 0 {
+		return 0, false
+	}
+	x := h.items[0]
+	h.items[0] = h.items[n-1]
+	h.items = h.items[:n-1]
+	if len(h.items) > 0 {
+		h.minDown(0)
+	}
+	return x, true
+}
+
+// PopMax removes and returns the largest value. ok is false, and the heap
+// is left untouched, when it is empty.
+func (h *MinMaxHeap) PopMax() (int, bool) {
+	n := len(h.items)
+	if n == 0 {
+		return 0, false
+	}
+	top := h.hi(0)
+	x := h.items[top]
+	h.items[top] = h.items[n-1]
+	h.items = h.items[:n-1]
+	if len(h.items) > 0 {
+		h.maxDown(0)
+	}
+	return x, true
+}
+
+// minDown sinks node k's low end below any child whose low end is smaller,
+// repairing each visited interval on the way.
+func (h *MinMaxHeap) minDown(k int) {
+	n := len(h.items)
+	for {
+		if lo, hi := 2*k, 2*k+1; hi < n && h.items[lo] > h.items[hi] {
+			h.items[lo], h.items[hi] = h.items[hi], h.items[lo]
+		}
+		c := 2*k + 1
+		if 2*c >= n {
+			return // Leaf
+		}
+		if d := c + 1; 2*d < n && h.items[2*d] < h.items[2*c] {
+			c = d
+		}
+		if h.items[2*c] >= h.items[2*k] {
+			return
+		}
+		h.items[2*c], h.items[2*k] = h.items[2*k], h.items[2*c]
+		k = c
+	}
+}
+
+// maxDown sinks node k's high end below any child whose high end is larger,
+// repairing each visited interval on the way.
+func (h *MinMaxHeap) maxDown(k int) {
+	n := len(h.items)
+	for {
+		if lo, hi := 2*k, 2*k+1; hi < n && h.items[lo] > h.items[hi] {
+			h.items[lo], h.items[hi] = h.items[hi], h.items[lo]
+		}
+		c := 2*k + 1
+		if 2*c >= n {
+			return // Leaf
+		}
+		if d := c + 1; 2*d < n && h.items[h.hi(d)] > h.items[h.hi(c)] {
+			c = d
+		}
+		top, child := h.hi(k), h.hi(c)
+		if h.items[child] <= h.items[top] {
+			return
+		}
+		h.items[child], h.items[top] = h.items[top], h.items[child]
+		k = c
+	}
+}
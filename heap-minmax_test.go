@@ -0,0 +1,101 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// checkIntervalHeap fails the test unless every node's interval is ordered
+// and contains both of its children's intervals.
+func checkIntervalHeap(t testing.TB, h *MinMaxHeap) {
+	t.Helper()
+	n := len(h.items)
+	for k := 0; 2*k < n; k++ {
+		lo, hi := h.items[2*k], h.items[h.hi(k)]
+		if lo > hi {
+			t.Fatalf("node %d has low end %d above high end %d: %v", k, lo, hi, h.items)
+		}
+		if k > 0 {
+			p := (k - 1) / 2
+			if lo < h.items[2*p] || hi > h.items[h.hi(p)] {
+				t.Fatalf("node %d [%d, %d] escapes parent [%d, %d]: %v", k, lo, hi, h.items[2*p], h.items[h.hi(p)], h.items)
+			}
+		}
+	}
+}
+
+// TestMinMaxHeapInterleavings mixes Insert, PopMin and PopMax at random and
+// compares every result with a sorted slice.
+func TestMinMaxHeapInterleavings(t *testing.T) {
+	rng := newTestRand(173)
+	h := NewMinMaxHeap()
+	var model []int // Kept sorted
+	for step := range 5000 {
+		switch op := rng.IntN(5); {
+		case op < 3 || len(model) == 0:
+			x := rng.IntN(100)
+			h.Insert(x)
+			i, _ := slices.BinarySearch(model, x)
+			model = slices.Insert(model, i, x)
+		case op == 3:
+			got, ok := h.PopMin()
+			if !ok || got != model[0] {
+				t.Fatalf("step %d: PopMin() = %d, %t; want %d", step, got, ok, model[0])
+			}
+			model = model[1:]
+		default:
+			got, ok := h.PopMax()
+			if !ok || got != model[len(model)-1] {
+				t.Fatalf("step %d: PopMax() = %d, %t; want %d", step, got, ok, model[len(model)-1])
+			}
+			model = model[:len(model)-1]
+		}
+		checkIntervalHeap(t, h)
+		if h.Len() != len(model) {
+			t.Fatalf("step %d: Len() = %d, want %d", step, h.Len(), len(model))
+		}
+		if len(model) > 0 {
+			lo, _ := h.Min()
+			hi, _ := h.Max()
+			if lo != model[0] || hi != model[len(model)-1] {
+				t.Fatalf("step %d: Min(), Max() = %d, %d; want %d, %d", step, lo, hi, model[0], model[len(model)-1])
+			}
+		}
+	}
+}
+
+func TestMinMaxHeapSmall(t *testing.T) {
+	h := NewMinMaxHeap()
+	for _, probe := range []func() (int, bool){h.Min, h.Max, h.PopMin, h.PopMax} {
+		if _, ok := probe(); ok {
+			t.Fatal("query on an empty MinMaxHeap ok = true")
+		}
+	}
+
+	h.Insert(5) // A single value is both ends
+	if lo, _ := h.Min(); lo != 5 {
+		t.Errorf("Min() = %d, want 5", lo)
+	}
+	if hi, _ := h.Max(); hi != 5 {
+		t.Errorf("Max() = %d, want 5", hi)
+	}
+	if x, ok := h.PopMax(); !ok || x != 5 || h.Len() != 0 {
+		t.Errorf("PopMax() = %d, %t leaving Len() %d; want 5, true, 0", x, ok, h.Len())
+	}
+
+	for _, x := range []int{3, 3, 1, 3} {
+		h.Insert(x)
+	}
+	var got []int
+	for h.Len() > 0 {
+		lo, _ := h.PopMin()
+		got = append(got, lo)
+		if h.Len() > 0 {
+			hi, _ := h.PopMax()
+			got = append(got, hi)
+		}
+	}
+	if want := []int{1, 3, 3, 3}; !slices.Equal(got, want) {
+		t.Errorf("alternating pops = %v, want %v", got, want)
+	}
+}
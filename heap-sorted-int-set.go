@@ -0,0 +1,124 @@
+package main
+
+import "sort"
+
+// setNode is a node of a SortedIntSet's balanced BST.
+type setNode struct {
+	value       int
+	left, right *setNode
+	size        int // Number of values in this subtree, used for Rank
+}
+
+// SortedIntSet is an immutable, perfectly balanced binary search tree of
+// unique ints, offering ordered traversal and Floor/Ceiling/Rank queries in
+// O(log n). Build one from an ItemHeap with ToSortedSet.
+type SortedIntSet struct {
+	root *setNode
+}
+
+// ToSortedSet returns the heap's distinct values as a SortedIntSet, ordered
+// ascending regardless of the heap's comparator. The heap itself is left unchanged.
+// Since an ItemHeap may hold several copies of a value, the set can be smaller
+// than the heap: its in-order traversal is the heap's sorted contents with
+// duplicates collapsed. Use Count for how many copies of a value there are.
+func (h *ItemHeap) ToSortedSet() *SortedIntSet {
+	values := make([]int, 0, len(h.index))
+	for v := range h.index {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+	return &SortedIntSet{root: buildBalanced(values)}
+}
+
+// buildBalanced builds a balanced BST from sorted values by rooting each
+// subtree at its middle element.
+func buildBalanced(values []int) *setNode {
+	if len(values) == 0 {
+		return nil
+	}
+	mid := len(values) / 2
+	return &setNode{
+		value: values[mid],
+		left:  buildBalanced(values[:mid]),
+		right: buildBalanced(values[mid+1:]),
+		size:  len(values),
+	}
+}
+
+func sizeOf(n *setNode) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+// Len returns the number of values in the set.
+func (s *SortedIntSet) Len() int {
+	return sizeOf(s.root)
+}
+
+// Values returns every value in ascending order.
+func (s *SortedIntSet) Values() []int {
+	values := make([]int, 0, s.Len())
+	s.InOrder(func(v int) bool {
+		values = append(values, v)
+		return true
+	})
+	return values
+}
+
+// InOrder calls fn for each value in ascending order until fn returns false.
+func (s *SortedIntSet) InOrder(fn func(v int) bool) {
+	inOrder(s.root, fn)
+}
+
+func inOrder(n *setNode, fn func(v int) bool) bool {
+	if n == nil {
+		return true
+	}
+	return inOrder(n.left, fn) && fn(n.value) && inOrder(n.right, fn)
+}
+
+// Floor returns the largest value <= x. ok is false if there is none.
+func (s *SortedIntSet) Floor(x int) (int, bool) {
+	var best int
+	found := false
+	for n := s.root; n != nil; {
+		if n.value <= x {
+			best, found = n.value, true
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return best, found
+}
+
+// Ceiling returns the smallest value >= x. ok is false if there is none.
+func (s *SortedIntSet) Ceiling(x int) (int, bool) {
+	var best int
+	found := false
+	for n := s.root; n != nil; {
+		if n.value >= x {
+			best, found = n.value, true
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return best, found
+}
+
+// Rank returns the number of values strictly less than x.
+func (s *SortedIntSet) Rank(x int) int {
+	rank := 0
+	for n := s.root; n != nil; {
+		if n.value < x {
+			rank += sizeOf(n.left) + 1
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return rank
+}
@@ -3,69 +3,499 @@ package main
 import (
 	"container/heap"
 	"fmt"
+	"slices"
 )
 
+// Go maps never release buckets after deletes, so an index map that once held
+// many values stays that big. Once the heap has shrunk to a quarter of the
+// map's high-water mark, and that mark is at least indexCompactMin entries,
+// the map is rebuilt at the current size.
+const (
+	indexCompactMin   = 1024
+	indexCompactRatio = 4
+)
+
+// ItemHeap is a min-heap of ints that also tracks each value's slots, so
+// arbitrary values can be removed in O(log n). Values may repeat: every copy
+// has its own slot, Len counts them all and Remove takes out one at a time.
+//
+// *ItemHeap satisfies heap.Interface and can be handed to container/heap
+// directly: heap.Push(h, x) and heap.Pop(h) keep the index map consistent
+// because Swap, Push and Pop all maintain it. Insert and Remove are the
+// convenience wrappers for callers that don't need the raw interface.
 type ItemHeap struct {
 	items []int
-	index map[int]int // item -> index in heap
+	index map[int][]int // item -> indexes of its copies in heap, in no particular order
+	at    []int         // at[i] is where slot i is listed in index[items[i]]
+	less  func(a, b int) bool
+
+	indexPeak int // Most entries index has held since it was last allocated
+
+	onMinChange func(newMin int, ok bool) // Optional observer, see OnMinChange
 }
 
+var _ heap.Interface = (*ItemHeap)(nil)
+
 func NewItemHeap() *ItemHeap {
+	return NewItemHeapFunc(func(a, b int) bool { return a < b })
+}
+
+// NewMaxItemHeap creates an ItemHeap with the largest value on top, so GetMin,
+// Peek and PopMin return the maximum. It is shorthand for NewItemHeapFunc with
+// a > comparator, which Less, Insert, Remove and Fix all go through.
+func NewMaxItemHeap() *ItemHeap {
+	return NewItemHeapFunc(func(a, b int) bool { return a > b })
+}
+
+// NewItemHeapFunc creates an ItemHeap ordered by less instead of plain <.
+// The value for which less reports true against every other value sits at the top.
+//
+// less should compare with < / > directly (or compare the derived keys that way);
+// subtraction-based comparators such as a-b < 0 overflow near math.MaxInt/MinInt.
+func NewItemHeapFunc(less func(a, b int) bool) *ItemHeap {
 	return &ItemHeap{
 		items: []int{},
-		index: make(map[int]int),
+		index: make(map[int][]int),
+		at:    []int{},
+		less:  less,
+	}
+}
+
+// NewItemHeapFromSlice creates a min-heap holding a copy of items, built with
+// one O(n) heapify rather than n Inserts. Later changes to items don't affect
+// the heap.
+func NewItemHeapFromSlice(items []int) *ItemHeap {
+	h := NewItemHeap()
+	h.items = slices.Clone(items)
+	if h.items == nil {
+		h.items = []int{}
 	}
+	h.reindex()
+	heap.Init(h)
+	return h
 }
 
 func (h *ItemHeap) Len() int           { return len(h.items) }
-func (h *ItemHeap) Less(i, j int) bool { return h.items[i] < h.items[j] }
+func (h *ItemHeap) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
 func (h *ItemHeap) Swap(i, j int) {
-	h.items[i], h.items[j] = h.items[j], h.items[i]
-	h.index[h.items[i]] = i
-	h.index[h.items[j]] = j
+	a, b := h.items[i], h.items[j]
+	if a == b {
+		return // Two copies of one value: its set of slots stays the same
+	}
+	h.items[i], h.items[j] = b, a
+	h.index[a][h.at[i]] = j
+	h.index[b][h.at[j]] = i
+	h.at[i], h.at[j] = h.at[j], h.at[i]
 }
 
 func (h *ItemHeap) Push(x any) {
 	item := x.(int)
-	h.index[item] = len(h.items)
+	h.addSlot(item, len(h.items))
 	h.items = append(h.items, item)
+	h.indexPeak = max(h.indexPeak, len(h.index))
 }
 
 func (h *ItemHeap) Pop() any {
 	n := len(h.items)
 	item := h.items[n-1]
+	h.dropSlot(item, n-1)
 	h.items = h.items[:n-1]
-	delete(h.index, item)
+	h.at = h.at[:n-1]
+	h.maybeCompactIndex()
 	return item
 }
 
+// addSlot records a copy of x at slot, which is either a new slot at the end
+// of items or one just vacated by dropSlot.
+func (h *ItemHeap) addSlot(x, slot int) {
+	if slot == len(h.at) {
+		h.at = append(h.at, 0)
+	}
+	h.at[slot] = len(h.index[x])
+	h.index[x] = append(h.index[x], slot)
+}
+
+// dropSlot forgets the copy of x at slot, and x itself once no copies remain.
+// The slot listed last for x takes its place, so this is O(1) however many
+// copies x has.
+func (h *ItemHeap) dropSlot(x, slot int) {
+	slots := h.index[x]
+	if len(slots) == 1 {
+		delete(h.index, x)
+		return
+	}
+	k, last := h.at[slot], slots[len(slots)-1]
+	slots[k] = last
+	h.at[last] = k
+	h.index[x] = slots[:len(slots)-1]
+}
+
 func (h *ItemHeap) Init() {
+	prev, prevOK := h.Peek()
 	heap.Init(h)
+	h.notifyMin(prev, prevOK)
 }
 
 func (h *ItemHeap) Insert(x int) {
+	prev, prevOK := h.Peek()
 	heap.Push(h, x)
+	h.notifyMin(prev, prevOK)
+}
+
+// OnMinChange registers fn to be called whenever an ItemHeap method changes the
+// top value, with ok=false once the heap becomes empty. Operations that leave
+// the top unchanged don't fire it. Only one observer is kept; nil removes it.
+// Driving the heap through container/heap directly bypasses the observer.
+func (h *ItemHeap) OnMinChange(fn func(newMin int, ok bool)) {
+	h.onMinChange = fn
 }
 
+// notifyMin fires the observer if the top differs from prev/prevOK.
+func (h *ItemHeap) notifyMin(prev int, prevOK bool) {
+	if h.onMinChange == nil {
+		return
+	}
+	cur, ok := h.Peek()
+	if ok != prevOK || (ok && cur != prev) {
+		h.onMinChange(cur, ok)
+	}
+}
+
+// GetMin returns the top value without removing it.
+// It panics on an empty heap; use Peek when the heap may be empty.
 func (h *ItemHeap) GetMin() int {
+	if len(h.items) == 0 {
+		panic("heap: GetMin called on empty ItemHeap")
+	}
 	return h.items[0]
 }
 
+// Peek is the non-panicking GetMin: ok is false when the heap is empty.
+func (h *ItemHeap) Peek() (int, bool) {
+	if len(h.items) == 0 {
+		return 0, false
+	}
+	return h.items[0], true
+}
+
+// MinMax returns the top value and the value at the opposite end of the heap's
+// ordering (the maximum, for a min-heap) in one call; ok is false when the heap
+// is empty. The opposite end is always a leaf, so only the last half of items
+// is scanned.
+func (h *ItemHeap) MinMax() (minVal, maxVal int, ok bool) {
+	n := len(h.items)
+	if n == 0 {
+		return 0, 0, false
+	}
+	maxVal = h.items[n-1]
+	for _, item := range h.items[n/2:] {
+		if h.less(maxVal, item) {
+			maxVal = item
+		}
+	}
+	return h.items[0], maxVal, true
+}
+
+// PopMin removes and returns the top value. ok is false, and the heap is left
+// untouched, when it is empty.
+func (h *ItemHeap) PopMin() (int, bool) {
+	if len(h.items) == 0 {
+		return 0, false
+	}
+	x := heap.Pop(h).(int)
+	h.notifyMin(x, true)
+	return x, true
+}
+
+// ExtractMin is another name for PopMin: it removes and returns the value
+// GetMin would have returned, with ok=false on an empty heap.
+func (h *ItemHeap) ExtractMin() (int, bool) {
+	return h.PopMin()
+}
+
+// Remove deletes one copy of x from the heap, reporting whether x was present.
+// On an empty heap it returns false without touching items or index.
 func (h *ItemHeap) Remove(x int) bool {
-	i, ok := h.index[x]
+	if len(h.items) == 0 {
+		return false
+	}
+	slots, ok := h.index[x]
+	if !ok {
+		return false
+	}
+	prev := h.items[0]
+	heap.Remove(h, slots[0])
+	h.notifyMin(prev, true)
+	return true
+}
+
+// Update replaces one copy of old with new in place and restores the heap
+// order, e.g. to decrease a key in Dijkstra's algorithm. It is cheaper than
+// Remove followed by Insert, since the value keeps its slot and only sifts
+// from there. It returns false, changing nothing, if old isn't present.
+func (h *ItemHeap) Update(old, new int) bool {
+	slots, ok := h.index[old]
 	if !ok {
 		return false
 	}
-	last := len(h.items) - 1
-	h.Swap(i, last)
-	h.items = h.items[:last]
-	delete(h.index, x)
-	if i < len(h.items) {
-		heap.Fix(h, i)
+	if old == new {
+		return true
 	}
+	prev, prevOK := h.Peek()
+	i := slots[0]
+	h.dropSlot(old, i)
+	h.items[i] = new
+	h.addSlot(new, i)
+	h.indexPeak = max(h.indexPeak, len(h.index))
+	heap.Fix(h, i)
+	h.notifyMin(prev, prevOK)
 	return true
 }
-func main() {
+
+// IndexOf returns the slot of a copy of x in the heap's backing slice.
+// When x occurs more than once, which copy is unspecified.
+func (h *ItemHeap) IndexOf(x int) (int, bool) {
+	slots, ok := h.index[x]
+	if !ok {
+		return 0, false
+	}
+	return slots[0], true
+}
+
+// Count returns how many copies of x the heap holds.
+func (h *ItemHeap) Count(x int) int {
+	return len(h.index[x])
+}
+
+// Fix restores the heap order after the value at slot i changed its ordering,
+// e.g. because state captured by a NewItemHeapFunc comparator was updated.
+// Use IndexOf to find the slot. It panics if i is out of range.
+func (h *ItemHeap) Fix(i int) {
+	prev, prevOK := h.Peek()
+	heap.Fix(h, i)
+	h.notifyMin(prev, prevOK)
+}
+
+// RemoveSet removes every copy of each value in values that is present in
+// the heap and returns how many copies were removed. It filters items in a
+// single pass, rebuilds the index map and re-heapifies once, which beats
+// calling Remove per value when many values go at once.
+func (h *ItemHeap) RemoveSet(values map[int]struct{}) int {
+	prev, prevOK := h.Peek() // Read before filtering in place overwrites items
+	kept := h.items[:0]
+	for _, item := range h.items {
+		if _, drop := values[item]; !drop {
+			kept = append(kept, item)
+		}
+	}
+	removed := len(h.items) - len(kept)
+	if removed == 0 {
+		return 0
+	}
+
+	h.items = kept
+	h.reindex()
+	heap.Init(h)
+	h.notifyMin(prev, prevOK)
+	return removed
+}
+
+// Merge adds every value of other to h with a single O(n) re-heapify instead
+// of one Insert per value. Values present in both heaps end up as duplicates,
+// as with Insert. other is left untouched, and its comparator is ignored in
+// favour of h's.
+func (h *ItemHeap) Merge(other *ItemHeap) {
+	if len(other.items) == 0 {
+		return
+	}
+	prev, prevOK := h.Peek()
+	h.items = append(h.items, other.items...)
+	h.reindex()
+	heap.Init(h)
+	h.notifyMin(prev, prevOK)
+}
+
+// Rebuild makes the heap consistent again after its items slice was edited
+// behind its back: it recomputes the index map from items and re-heapifies in
+// O(n). Since the previous top is unknown, a registered OnMinChange observer
+// is always called with the new top.
+func (h *ItemHeap) Rebuild() {
+	h.reindex()
+	heap.Init(h)
+	if h.onMinChange != nil {
+		top, ok := h.Peek()
+		h.onMinChange(top, ok)
+	}
+}
+
+// reindex replaces the index map with one built from the current items.
+func (h *ItemHeap) reindex() {
+	h.index = make(map[int][]int, len(h.items))
+	h.at = make([]int, len(h.items))
+	for i, item := range h.items {
+		h.at[i] = len(h.index[item])
+		h.index[item] = append(h.index[item], i)
+	}
+	h.indexPeak = len(h.index)
+}
+
+// CompactIndex reallocates the index map at the heap's current size, handing
+// the buckets left over from a larger past back to the garbage collector.
+// Pop and Remove already do this automatically once the heap has shrunk far
+// enough (see indexCompactRatio); call it directly after a bulk removal when
+// memory must be reclaimed right away. It does not change the heap order.
+func (h *ItemHeap) CompactIndex() {
+	h.reindex()
+}
+
+// maybeCompactIndex calls CompactIndex once the live entries fall below
+// 1/indexCompactRatio of the map's high-water mark.
+func (h *ItemHeap) maybeCompactIndex() {
+	if h.indexPeak >= indexCompactMin && len(h.index)*indexCompactRatio <= h.indexPeak {
+		h.CompactIndex()
+	}
+}
+
+// Clone returns a deep copy of the heap. The clone owns its own items slice
+// and index map, so mutating it never affects h.
+func (h *ItemHeap) Clone() *ItemHeap {
+	c := &ItemHeap{
+		items: make([]int, len(h.items)),
+		index: make(map[int][]int, len(h.index)),
+		at:    slices.Clone(h.at),
+		less:  h.less,
+
+		indexPeak: len(h.index),
+	}
+	copy(c.items, h.items)
+	for item, slots := range h.index {
+		c.index[item] = slices.Clone(slots)
+	}
+	return c
+}
+
+// Reverse flips the heap's ordering in place and re-heapifies the existing
+// items in O(n), so a min-heap becomes a max-heap (and back again).
+// After Reverse, GetMin returns what was previously the largest value.
+func (h *ItemHeap) Reverse() {
+	less := h.less
+	h.less = func(a, b int) bool { return less(b, a) }
+	h.Init() // Swap keeps the index map in sync while re-heapifying
+}
+
+// PeekN returns the n values that would be popped first, in pop order, without
+// modifying the heap. If n exceeds Len, all values are returned sorted.
+// It explores the heap from the root with a small candidate heap of slots,
+// so it costs O(n log n) regardless of how large the heap is.
+func (h *ItemHeap) PeekN(n int) []int {
+	if n > len(h.items) {
+		n = len(h.items)
+	}
+	if n <= 0 {
+		return []int{}
+	}
+
+	result := make([]int, 0, n)
+	// Candidates are slots in h.items, ordered by the values stored there.
+	candidates := &boundedHeap{
+		items: []int{0},
+		less:  func(a, b int) bool { return h.less(h.items[a], h.items[b]) },
+	}
+	for len(result) < n {
+		i := heap.Pop(candidates).(int)
+		result = append(result, h.items[i])
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child < len(h.items) {
+				heap.Push(candidates, child)
+			}
+		}
+	}
+	return result
+}
+
+// TopK returns the k smallest values (the top k under the heap's comparator)
+// in sorted order, leaving the heap unchanged; it is another name for PeekN.
+// With k > Len every value is returned.
+func (h *ItemHeap) TopK(k int) []int {
+	return h.PeekN(k)
+}
+
+// Drain empties the heap and returns its values in pop order (ascending for a min-heap).
+func (h *ItemHeap) Drain() []int {
+	result := make([]int, 0, len(h.items))
+	for h.Len() > 0 {
+		x, _ := h.PopMin()
+		result = append(result, x)
+	}
+	return result
+}
+
+// DrainDescending empties the heap and returns its values in reverse pop
+// order (largest first for a min-heap).
+func (h *ItemHeap) DrainDescending() []int {
+	result := h.Drain()
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// boundedHeap is a plain heap.Interface over ints ordered by less, for
+// callers that never need to find a value again and so can skip ItemHeap's
+// index map.
+type boundedHeap struct {
+	items []int
+	less  func(a, b int) bool
+}
+
+func (h *boundedHeap) Len() int           { return len(h.items) }
+func (h *boundedHeap) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h *boundedHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *boundedHeap) Push(x any)         { h.items = append(h.items, x.(int)) }
+func (h *boundedHeap) Pop() any {
+	n := len(h.items)
+	item := h.items[n-1]
+	h.items = h.items[:n-1]
+	return item
+}
+
+// kthBy keeps the k "best" values of nums under less in a heap whose root is
+// the worst of them, so the root ends up being the k-th best value.
+// Runs in O(n log k).
+func kthBy(nums []int, k int, less func(a, b int) bool) (int, bool) {
+	if k < 1 || k > len(nums) {
+		return 0, false
+	}
+	// The root must be the worst retained value, so the heap orders by !less.
+	h := &boundedHeap{
+		items: make([]int, 0, k),
+		less:  func(a, b int) bool { return less(b, a) },
+	}
+	for _, x := range nums {
+		if h.Len() < k {
+			heap.Push(h, x)
+		} else if less(x, h.items[0]) {
+			h.items[0] = x
+			heap.Fix(h, 0)
+		}
+	}
+	return h.items[0], true
+}
+
+// KthSmallest returns the k-th smallest value in nums (k is 1-based).
+// ok is false when k is outside [1, len(nums)].
+func KthSmallest(nums []int, k int) (int, bool) {
+	return kthBy(nums, k, func(a, b int) bool { return a < b })
+}
+
+// KthLargest returns the k-th largest value in nums (k is 1-based).
+// ok is false when k is outside [1, len(nums)].
+func KthLargest(nums []int, k int) (int, bool) {
+	return kthBy(nums, k, func(a, b int) bool { return a > b })
+}
+
+// itemHeapDemo exercises ItemHeap; run it with "go run . itemheap".
+func itemHeapDemo() {
 	h := NewItemHeap()
 	h.Init()
 
@@ -77,4 +507,14 @@ func main() {
 
 	h.Remove(3)
 	fmt.Println("Min after removing 3:", h.GetMin()) // 5
+
+	h.Insert(1)
+	h.Insert(9)
+	fmt.Println("Two smallest:", h.PeekN(2)) // [1 5]
+
+	nums := []int{7, 2, 9, 4, 4, 1}
+	kth, _ := KthSmallest(nums, 2)
+	fmt.Println("2nd smallest:", kth) // 2
+	kth, _ = KthLargest(nums, 2)
+	fmt.Println("2nd largest:", kth) // 7
 }
@@ -0,0 +1,101 @@
+package main
+
+import "container/heap"
+
+// stableItem pairs a priority with the sequence number it was inserted under.
+type stableItem struct {
+	priority int
+	seq      uint64
+}
+
+// StableItemHeap is a min-heap that pops equal priorities in FIFO order.
+// Each Insert is tagged with an increasing sequence number that breaks ties,
+// and that number doubles as the handle for Remove, so duplicate priorities
+// are fine here (unlike ItemHeap, whose index map is keyed by value).
+type StableItemHeap struct {
+	items   []stableItem
+	index   map[uint64]int // seq -> index in heap
+	nextSeq uint64
+}
+
+var _ heap.Interface = (*StableItemHeap)(nil)
+
+func NewStableItemHeap() *StableItemHeap {
+	return &StableItemHeap{
+		items: []stableItem{},
+		index: make(map[uint64]int),
+	}
+}
+
+func (h *StableItemHeap) Len() int { return len(h.items) }
+func (h *StableItemHeap) Less(i, j int) bool {
+	if h.items[i].priority != h.items[j].priority {
+		return h.items[i].priority < h.items[j].priority
+	}
+	return h.items[i].seq < h.items[j].seq
+}
+func (h *StableItemHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.index[h.items[i].seq] = i
+	h.index[h.items[j].seq] = j
+}
+
+func (h *StableItemHeap) Push(x any) {
+	item := x.(stableItem)
+	h.index[item.seq] = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *StableItemHeap) Pop() any {
+	n := len(h.items)
+	item := h.items[n-1]
+	h.items = h.items[:n-1]
+	delete(h.index, item.seq)
+	return item
+}
+
+// Insert adds priority and returns a handle identifying this particular entry.
+func (h *StableItemHeap) Insert(priority int) uint64 {
+	seq := h.nextSeq
+	h.nextSeq++
+	heap.Push(h, stableItem{priority: priority, seq: seq})
+	return seq
+}
+
+// GetMin returns the smallest priority; among equals, the one inserted first.
+// It panics on an empty heap; use Peek when the heap may be empty.
+func (h *StableItemHeap) GetMin() int {
+	if len(h.items) == 0 {
+		panic("heap: GetMin called on empty StableItemHeap")
+	}
+	return h.items[0].priority
+}
+
+// Peek is the non-panicking GetMin: ok is false when the heap is empty.
+func (h *StableItemHeap) Peek() (int, bool) {
+	if len(h.items) == 0 {
+		return 0, false
+	}
+	return h.items[0].priority, true
+}
+
+// PopMin removes and returns the smallest priority, oldest first among equals,
+// along with the handle Insert returned for it so callers can tell equal
+// priorities apart. ok is false when the heap is empty.
+func (h *StableItemHeap) PopMin() (priority int, handle uint64, ok bool) {
+	if len(h.items) == 0 {
+		return 0, 0, false
+	}
+	item := heap.Pop(h).(stableItem)
+	return item.priority, item.seq, true
+}
+
+// Remove deletes the entry identified by handle, reporting whether it was present.
+func (h *StableItemHeap) Remove(handle uint64) bool {
+	i, ok := h.index[handle]
+	if !ok {
+		return false
+	}
+	heap.Remove(h, i)
+	return true
+}
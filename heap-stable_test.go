@@ -0,0 +1,72 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStableItemHeapFIFOTies(t *testing.T) {
+	h := NewStableItemHeap()
+	priorities := []int{2, 1, 2, 1, 2, 0, 1}
+	handles := make([]uint64, len(priorities))
+	for i, p := range priorities {
+		handles[i] = h.Insert(p)
+	}
+
+	// Ascending priority, insertion order within each priority.
+	wantOrder := []int{5, 1, 3, 6, 0, 2, 4}
+	for _, i := range wantOrder {
+		p, handle, ok := h.PopMin()
+		if !ok || p != priorities[i] || handle != handles[i] {
+			t.Fatalf("PopMin() = %d, %d, %t; want %d, %d, true (insert #%d)", p, handle, ok, priorities[i], handles[i], i)
+		}
+	}
+	if _, _, ok := h.PopMin(); ok {
+		t.Error("PopMin() on an empty heap ok = true, want false")
+	}
+}
+
+func TestStableItemHeapRemoveKeepsFIFO(t *testing.T) {
+	h := NewStableItemHeap()
+	var handles []uint64
+	for range 5 {
+		handles = append(handles, h.Insert(7))
+	}
+	if !h.Remove(handles[2]) || h.Remove(handles[2]) {
+		t.Fatal("Remove should succeed once per handle")
+	}
+	if h.Remove(99) {
+		t.Error("Remove(unknown handle) = true, want false")
+	}
+
+	var got []uint64
+	for h.Len() > 0 {
+		_, handle, _ := h.PopMin()
+		got = append(got, handle)
+	}
+	want := []uint64{handles[0], handles[1], handles[3], handles[4]}
+	if !slices.Equal(got, want) {
+		t.Errorf("pop order = %v, want %v", got, want)
+	}
+}
+
+func TestStableItemHeapEmpty(t *testing.T) {
+	h := NewStableItemHeap()
+	if _, ok := h.Peek(); ok {
+		t.Error("Peek() on an empty heap ok = true, want false")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("GetMin() on an empty heap did not panic")
+			}
+		}()
+		h.GetMin()
+	}()
+
+	h.Insert(4)
+	h.Insert(2)
+	if got, ok := h.Peek(); !ok || got != 2 || h.GetMin() != 2 {
+		t.Errorf("Peek() = %d, %t, GetMin() = %d; want 2, true, 2", got, ok, h.GetMin())
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"container/heap"
+	"time"
+)
+
+// ttlEntry is a TTLHeap value together with the time it expires.
+type ttlEntry struct {
+	value    any
+	deadline time.Time
+	seq      uint64 // Insertion order, so equal deadlines expire FIFO
+}
+
+// ttlEntries implements heap.Interface ordered by deadline.
+type ttlEntries []ttlEntry
+
+func (e ttlEntries) Len() int { return len(e) }
+func (e ttlEntries) Less(i, j int) bool {
+	if !e[i].deadline.Equal(e[j].deadline) {
+		return e[i].deadline.Before(e[j].deadline)
+	}
+	return e[i].seq < e[j].seq
+}
+func (e ttlEntries) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+func (e *ttlEntries) Push(x any)   { *e = append(*e, x.(ttlEntry)) }
+func (e *ttlEntries) Pop() any {
+	old := *e
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = ttlEntry{} // Don't keep the expired value reachable
+	*e = old[:n-1]
+	return entry
+}
+
+// TTLHeap holds values until their deadline passes, keeping the one that
+// expires soonest on top. Values whose deadline is at or before now count as
+// expired. Values can be taken out in bulk either by pulling (PopExpired) or
+// by having a callback pushed each one (Expire).
+type TTLHeap struct {
+	entries ttlEntries
+	nextSeq uint64
+}
+
+func NewTTLHeap() *TTLHeap {
+	return &TTLHeap{}
+}
+
+// Len returns the number of values still held, expired or not.
+func (h *TTLHeap) Len() int { return len(h.entries) }
+
+// Push adds value, to expire at deadline.
+func (h *TTLHeap) Push(value any, deadline time.Time) {
+	heap.Push(&h.entries, ttlEntry{value: value, deadline: deadline, seq: h.nextSeq})
+	h.nextSeq++
+}
+
+// NextDeadline returns the earliest deadline, which is when the next value
+// expires. ok is false when the heap is empty.
+func (h *TTLHeap) NextDeadline() (time.Time, bool) {
+	if len(h.entries) == 0 {
+		return time.Time{}, false
+	}
+	return h.entries[0].deadline, true
+}
+
+// PopExpired removes and returns every value whose deadline is at or before
+// now, in deadline order. It returns an empty slice when nothing has expired.
+func (h *TTLHeap) PopExpired(now time.Time) []any {
+	expired := []any{}
+	h.Expire(now, func(value any) { expired = append(expired, value) })
+	return expired
+}
+
+// Expire removes every value whose deadline is at or before now, calling
+// onExpire for each in deadline order (insertion order among equal
+// deadlines), and returns how many expired. onExpire may be nil. It is the
+// push-style counterpart of PopExpired, convenient to call from a ticker.
+func (h *TTLHeap) Expire(now time.Time, onExpire func(value any)) int {
+	n := 0
+	for len(h.entries) > 0 && !h.entries[0].deadline.After(now) {
+		entry := heap.Pop(&h.entries).(ttlEntry)
+		n++
+		if onExpire != nil {
+			onExpire(entry.value)
+		}
+	}
+	return n
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTTLHeapExpire(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	h := NewTTLHeap()
+	h.Push("c", at(3))
+	h.Push("a", at(1))
+	h.Push("future", at(60))
+	h.Push("b1", at(2))
+	h.Push("b2", at(2)) // Same deadline as b1, pushed later
+	h.Push("now", at(5))
+
+	var expired []any
+	collect := func(value any) { expired = append(expired, value) }
+
+	if n := h.Expire(at(0), collect); n != 0 || len(expired) != 0 {
+		t.Fatalf("Expire before any deadline = %d, called with %v", n, expired)
+	}
+	if n := h.Expire(at(5), collect); n != 5 {
+		t.Errorf("Expire(at 5m) = %d, want 5", n)
+	}
+	// Deadline order, FIFO among equal deadlines, and "at now" counts as expired.
+	if want := []any{"a", "b1", "b2", "c", "now"}; !slices.Equal(expired, want) {
+		t.Errorf("onExpire called with %v, want %v", expired, want)
+	}
+	if next, ok := h.NextDeadline(); !ok || !next.Equal(at(60)) || h.Len() != 1 {
+		t.Errorf("after Expire: NextDeadline() = %v, %t with Len() %d; want 60m, true, 1", next, ok, h.Len())
+	}
+
+	// Everything expired, with a nil callback.
+	h.Push("d", at(10))
+	if n := h.Expire(at(120), nil); n != 2 || h.Len() != 0 {
+		t.Errorf("Expire(everything, nil) = %d leaving Len() %d, want 2 and 0", n, h.Len())
+	}
+	if n := h.Expire(at(200), collect); n != 0 {
+		t.Errorf("Expire on an empty heap = %d, want 0", n)
+	}
+	if got := h.PopExpired(at(300)); got == nil || len(got) != 0 {
+		t.Errorf("PopExpired on an empty heap = %v, want []", got)
+	}
+}
@@ -27,8 +27,8 @@ func (h *IntHeap) Pop() any {
 }
 
 // This example inserts several ints into an IntHeap, checks the minimum,
-// and removes them in order of priority.
-func main() {
+// and removes them in order of priority. Run it with "go run . intheap".
+func intHeapDemo() {
 	h := &IntHeap{2, 1, 5}
 	heap.Init(h)
 	heap.Push(h, 3)
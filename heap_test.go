@@ -0,0 +1,986 @@
+package main
+
+import (
+	"container/heap"
+	"math"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// newItemHeapWith returns a min-heap built by inserting values in order.
+func newItemHeapWith(values ...int) *ItemHeap {
+	h := NewItemHeap()
+	for _, x := range values {
+		h.Insert(x)
+	}
+	return h
+}
+
+func TestItemHeapInsertGetMin(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []int
+		wantMin int
+	}{
+		{"single", []int{7}, 7},
+		{"ascending", []int{1, 2, 3, 4}, 1},
+		{"descending", []int{4, 3, 2, 1}, 1},
+		{"mixed", []int{5, 3, 8, 1, 9}, 1},
+		{"negative", []int{0, -5, 3}, -5},
+		{"all equal", []int{2, 2, 2}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewItemHeap()
+			for _, x := range tt.values {
+				h.Insert(x)
+				checkHeapInvariant(t, h)
+			}
+			if got := h.GetMin(); got != tt.wantMin {
+				t.Errorf("GetMin() = %d, want %d", got, tt.wantMin)
+			}
+			if got := h.Len(); got != len(tt.values) {
+				t.Errorf("Len() = %d, want %d", got, len(tt.values))
+			}
+		})
+	}
+}
+
+func TestItemHeapRemove(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []int
+		remove  int
+		want    bool
+		wantMin int // Ignored when the heap ends up empty
+	}{
+		{"root", []int{5, 3, 8}, 3, true, 5},
+		{"leaf", []int{1, 5, 3, 8}, 8, true, 1},
+		{"last slot", []int{1, 2, 3}, 3, true, 1},
+		{"inner node", []int{1, 4, 2, 6, 5, 3}, 4, true, 1},
+		{"only element", []int{42}, 42, true, 0},
+		{"nonexistent", []int{1, 2, 3}, 7, false, 1},
+		{"one of two copies", []int{2, 2, 5}, 2, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newItemHeapWith(tt.values...)
+			if got := h.Remove(tt.remove); got != tt.want {
+				t.Fatalf("Remove(%d) = %t, want %t", tt.remove, got, tt.want)
+			}
+			checkHeapInvariant(t, h)
+
+			wantLen := len(tt.values)
+			if tt.want {
+				wantLen--
+			}
+			if got := h.Len(); got != wantLen {
+				t.Errorf("Len() = %d, want %d", got, wantLen)
+			}
+			if wantLen > 0 {
+				if got := h.GetMin(); got != tt.wantMin {
+					t.Errorf("GetMin() = %d, want %d", got, tt.wantMin)
+				}
+			}
+		})
+	}
+}
+
+func TestItemHeapRemoveFromEmpty(t *testing.T) {
+	h := NewItemHeap()
+	if h.Remove(1) {
+		t.Error("Remove(1) on an empty heap = true, want false")
+	}
+	checkHeapInvariant(t, h)
+}
+
+func TestItemHeapGetMinEmptyPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("GetMin() on an empty heap did not panic")
+		}
+	}()
+	NewItemHeap().GetMin()
+}
+
+func TestItemHeapDuplicateInsert(t *testing.T) {
+	h := newItemHeapWith(3, 1, 3, 3)
+	checkHeapInvariant(t, h)
+	if got := h.Len(); got != 4 {
+		t.Fatalf("Len() = %d, want 4 (every copy is kept)", got)
+	}
+	if got := h.Count(3); got != 3 {
+		t.Fatalf("Count(3) = %d, want 3", got)
+	}
+
+	for want := 2; want >= 0; want-- {
+		if !h.Remove(3) {
+			t.Fatalf("Remove(3) = false with %d copies left", want+1)
+		}
+		checkHeapInvariant(t, h)
+		if got := h.Count(3); got != want {
+			t.Fatalf("Count(3) after Remove = %d, want %d", got, want)
+		}
+	}
+	if h.Remove(3) {
+		t.Error("Remove(3) after removing every copy = true, want false")
+	}
+	if got := h.Drain(); !slices.Equal(got, []int{1}) {
+		t.Errorf("Drain() = %v, want [1]", got)
+	}
+}
+
+func TestItemHeapSequence(t *testing.T) {
+	type op struct {
+		insert, remove int
+		isRemove       bool
+	}
+	ins := func(x int) op { return op{insert: x} }
+	rem := func(x int) op { return op{remove: x, isRemove: true} }
+
+	ops := []op{
+		ins(5), ins(3), ins(8), rem(3), ins(1), ins(9), ins(4),
+		rem(1), rem(9), ins(4), rem(4), rem(100), ins(2), rem(5),
+	}
+	h := NewItemHeap()
+	var model []int // Sorted multiset the heap should hold
+	for i, o := range ops {
+		if o.isRemove {
+			k := slices.Index(model, o.remove)
+			if got := h.Remove(o.remove); got != (k >= 0) {
+				t.Fatalf("op %d: Remove(%d) = %t, want %t", i, o.remove, got, k >= 0)
+			}
+			if k >= 0 {
+				model = slices.Delete(model, k, k+1)
+			}
+		} else {
+			h.Insert(o.insert)
+			model = append(model, o.insert)
+			slices.Sort(model)
+		}
+		checkHeapInvariant(t, h)
+		if h.Len() != len(model) {
+			t.Fatalf("op %d: Len() = %d, want %d", i, h.Len(), len(model))
+		}
+		if len(model) > 0 && h.GetMin() != model[0] {
+			t.Fatalf("op %d: GetMin() = %d, want %d", i, h.GetMin(), model[0])
+		}
+	}
+	if got := h.Drain(); !slices.Equal(got, model) {
+		t.Errorf("Drain() = %v, want %v", got, model)
+	}
+}
+
+// TestItemHeapRandomOps cross-checks random inserts and removals, with many
+// duplicates, against a sorted slice.
+func TestItemHeapRandomOps(t *testing.T) {
+	rng := newTestRand(143)
+	h := NewItemHeap()
+	var model []int
+	for step := range 2000 {
+		x := rng.IntN(20)
+		if rng.IntN(3) == 0 {
+			k := slices.Index(model, x)
+			if got := h.Remove(x); got != (k >= 0) {
+				t.Fatalf("step %d: Remove(%d) = %t, want %t", step, x, got, k >= 0)
+			}
+			if k >= 0 {
+				model = slices.Delete(model, k, k+1)
+			}
+		} else {
+			h.Insert(x)
+			model = append(model, x)
+			slices.Sort(model)
+		}
+		checkHeapInvariant(t, h)
+		if len(model) > 0 && h.GetMin() != model[0] {
+			t.Fatalf("step %d: GetMin() = %d, want %d", step, h.GetMin(), model[0])
+		}
+	}
+	if got := h.Drain(); !slices.Equal(got, model) {
+		t.Errorf("Drain() = %v, want %v", got, model)
+	}
+}
+
+func TestKthSmallestAndLargest(t *testing.T) {
+	rng := newTestRand(106)
+	inputs := [][]int{
+		{7},
+		{7, 2, 9, 4, 4, 1},
+		{3, 3, 3},
+		{-5, 0, 5, -10, 10},
+	}
+	for range 20 {
+		nums := make([]int, 1+rng.IntN(50))
+		for i := range nums {
+			nums[i] = rng.IntN(40) - 20
+		}
+		inputs = append(inputs, nums)
+	}
+
+	for _, nums := range inputs {
+		sorted := slices.Clone(nums)
+		slices.Sort(sorted)
+		original := slices.Clone(nums)
+		for k := 1; k <= len(nums); k++ {
+			if got, ok := KthSmallest(nums, k); !ok || got != sorted[k-1] {
+				t.Errorf("KthSmallest(%v, %d) = %d, %t, want %d, true", nums, k, got, ok, sorted[k-1])
+			}
+			if got, ok := KthLargest(nums, k); !ok || got != sorted[len(sorted)-k] {
+				t.Errorf("KthLargest(%v, %d) = %d, %t, want %d, true", nums, k, got, ok, sorted[len(sorted)-k])
+			}
+		}
+		if !slices.Equal(nums, original) {
+			t.Errorf("input was modified: %v, want %v", nums, original)
+		}
+	}
+}
+
+func TestKthOutOfRange(t *testing.T) {
+	for _, tt := range []struct {
+		nums []int
+		k    int
+	}{
+		{nil, 1},
+		{[]int{1, 2}, 0},
+		{[]int{1, 2}, -1},
+		{[]int{1, 2}, 3},
+	} {
+		if _, ok := KthSmallest(tt.nums, tt.k); ok {
+			t.Errorf("KthSmallest(%v, %d) ok = true, want false", tt.nums, tt.k)
+		}
+		if _, ok := KthLargest(tt.nums, tt.k); ok {
+			t.Errorf("KthLargest(%v, %d) ok = true, want false", tt.nums, tt.k)
+		}
+	}
+}
+
+func TestItemHeapCloneIsIndependent(t *testing.T) {
+	h := newItemHeapWith(5, 3, 8, 3, 1)
+	c := h.Clone()
+	checkHeapInvariant(t, c)
+
+	if got := c.Drain(); !slices.Equal(got, []int{1, 3, 3, 5, 8}) {
+		t.Fatalf("clone Drain() = %v, want [1 3 3 5 8]", got)
+	}
+	c.Insert(-1)
+	c.Insert(42)
+
+	checkHeapInvariant(t, h)
+	if got := h.GetMin(); got != 1 {
+		t.Errorf("original GetMin() = %d, want 1", got)
+	}
+	if h.Len() != 5 || h.Count(3) != 2 || h.Count(42) != 0 {
+		t.Errorf("original Len() = %d, Count(3) = %d, Count(42) = %d; want 5, 2, 0", h.Len(), h.Count(3), h.Count(42))
+	}
+
+	c2 := h.Clone()
+	h.Remove(3)
+	if c2.Count(3) != 2 {
+		t.Errorf("Remove on the original changed the clone: Count(3) = %d, want 2", c2.Count(3))
+	}
+	if got := h.Drain(); !slices.Equal(got, []int{1, 3, 5, 8}) {
+		t.Errorf("original Drain() = %v, want [1 3 5 8]", got)
+	}
+}
+
+func TestItemHeapCloneKeepsComparator(t *testing.T) {
+	c := NewMaxItemHeap()
+	c.Insert(1)
+	c.Insert(9)
+	if got := c.Clone().GetMin(); got != 9 {
+		t.Errorf("clone of a max-heap GetMin() = %d, want 9", got)
+	}
+}
+
+// TestItemHeapAsHeapInterface drives an ItemHeap purely through the
+// container/heap functions, as a caller handing it to other code would.
+func TestItemHeapAsHeapInterface(t *testing.T) {
+	h := NewItemHeap()
+	var hi heap.Interface = h
+	for _, x := range []int{5, 2, 8, 2, 7, 1} {
+		heap.Push(hi, x)
+		checkHeapInvariant(t, h)
+	}
+	if i, ok := h.IndexOf(8); !ok || h.items[i] != 8 {
+		t.Fatalf("IndexOf(8) = %d, %t after heap.Push", i, ok)
+	}
+
+	i, _ := h.IndexOf(7)
+	if got := heap.Remove(hi, i).(int); got != 7 {
+		t.Fatalf("heap.Remove at IndexOf(7) returned %d", got)
+	}
+	checkHeapInvariant(t, h)
+	if h.Count(7) != 0 {
+		t.Errorf("Count(7) = %d after heap.Remove, want 0", h.Count(7))
+	}
+
+	var popped []int
+	for h.Len() > 0 {
+		popped = append(popped, heap.Pop(hi).(int))
+		checkHeapInvariant(t, h)
+	}
+	if !slices.Equal(popped, []int{1, 2, 2, 5, 8}) {
+		t.Errorf("heap.Pop order = %v, want [1 2 2 5 8]", popped)
+	}
+	if len(h.index) != 0 {
+		t.Errorf("index = %v after popping everything, want empty", h.index)
+	}
+
+	// The wrappers keep working on the same heap afterwards.
+	heap.Push(hi, 3)
+	h.Insert(0)
+	if !h.Remove(3) || h.GetMin() != 0 {
+		t.Error("Insert/Remove disagree with values pushed through container/heap")
+	}
+}
+
+func TestItemHeapFuncNearIntLimits(t *testing.T) {
+	values := []int{0, math.MaxInt, math.MinInt, math.MaxInt - 1, math.MinInt + 1, -1, 1}
+	want := slices.Clone(values)
+	slices.Sort(want)
+
+	// A subtraction comparator such as a-b < 0 would misorder these pairs.
+	h := NewItemHeapFunc(func(a, b int) bool { return a < b })
+	for _, x := range values {
+		h.Insert(x)
+		checkHeapInvariant(t, h)
+	}
+	if got := h.Drain(); !slices.Equal(got, want) {
+		t.Errorf("Drain() = %v, want %v", got, want)
+	}
+
+	// Keys derived from the values, compared directly rather than subtracted.
+	byDistanceFromZero := NewItemHeapFunc(func(a, b int) bool {
+		return absDiff(a, 0) < absDiff(b, 0)
+	})
+	for _, x := range values {
+		byDistanceFromZero.Insert(x)
+	}
+	if got := byDistanceFromZero.Drain(); got[0] != 0 || got[len(got)-1] != math.MinInt {
+		t.Errorf("Drain() by |x| = %v, want 0 first and math.MinInt last", got)
+	}
+}
+
+// absDiff returns |a-b| as a uint64, which cannot overflow for any pair of ints.
+func absDiff(a, b int) uint64 {
+	if a < b {
+		return uint64(b) - uint64(a)
+	}
+	return uint64(a) - uint64(b)
+}
+
+func TestItemHeapReverse(t *testing.T) {
+	values := []int{5, 3, 8, 3, 1, 9, 4}
+	h := newItemHeapWith(values...)
+	h.Reverse()
+	checkHeapInvariant(t, h)
+	if got := h.GetMin(); got != 9 {
+		t.Fatalf("GetMin() after Reverse = %d, want 9", got)
+	}
+	if !h.Remove(3) || h.Count(3) != 1 {
+		t.Fatalf("Remove(3) after Reverse left Count(3) = %d, want 1", h.Count(3))
+	}
+	checkHeapInvariant(t, h)
+
+	var popped []int
+	for h.Len() > 0 {
+		x, _ := h.PopMin()
+		popped = append(popped, x)
+	}
+	if want := []int{9, 8, 5, 4, 3, 1}; !slices.Equal(popped, want) {
+		t.Errorf("PopMin order after Reverse = %v, want %v", popped, want)
+	}
+
+	twice := newItemHeapWith(values...)
+	twice.Reverse()
+	twice.Reverse()
+	if got := twice.GetMin(); got != 1 {
+		t.Errorf("GetMin() after reversing twice = %d, want 1", got)
+	}
+}
+
+func TestItemHeapPeekN(t *testing.T) {
+	rng := newTestRand(112)
+	for range 20 {
+		h := NewItemHeap()
+		for range rng.IntN(40) {
+			h.Insert(rng.IntN(25))
+		}
+		sorted := h.Clone().Drain()
+		before := slices.Clone(h.items)
+
+		for _, n := range []int{-1, 0, 1, 3, h.Len(), h.Len() + 5} {
+			want := sorted[:max(0, min(n, len(sorted)))]
+			if got := h.PeekN(n); !slices.Equal(got, want) || got == nil {
+				t.Errorf("PeekN(%d) = %v, want %v", n, got, want)
+			}
+		}
+		if !slices.Equal(h.items, before) {
+			t.Fatalf("PeekN modified the heap: %v, want %v", h.items, before)
+		}
+		checkHeapInvariant(t, h)
+	}
+}
+
+func TestItemHeapDrainDescending(t *testing.T) {
+	rng := newTestRand(124)
+	values := make([]int, 50)
+	for i := range values {
+		values[i] = i
+	}
+	rng.Shuffle(len(values), func(i, j int) { values[i], values[j] = values[j], values[i] })
+
+	for _, h := range []*ItemHeap{newItemHeapWith(values...), NewItemHeapFromSlice(values)} {
+		got := h.DrainDescending()
+		for i, x := range got {
+			if x != len(values)-1-i {
+				t.Fatalf("DrainDescending() = %v, want 49 down to 0", got)
+			}
+		}
+		if h.Len() != 0 || len(h.index) != 0 {
+			t.Errorf("heap not empty after DrainDescending: Len() = %d, index = %v", h.Len(), h.index)
+		}
+	}
+	if got := NewItemHeap().DrainDescending(); got == nil || len(got) != 0 {
+		t.Errorf("DrainDescending() on an empty heap = %v, want []", got)
+	}
+}
+
+// TestItemHeapEmptyOperations runs every non-panicking query and removal on
+// a heap that was never filled and on one that was filled and emptied again,
+// checking each reports "empty" and leaves items and index consistent.
+func TestItemHeapEmptyOperations(t *testing.T) {
+	emptied := newItemHeapWith(3, 1, 2)
+	emptied.Drain()
+	for name, h := range map[string]*ItemHeap{"new": NewItemHeap(), "emptied": emptied} {
+		t.Run(name, func(t *testing.T) {
+			ops := []struct {
+				name string
+				run  func() bool // Reports whether the result is correct for an empty heap
+			}{
+				{"Len", func() bool { return h.Len() == 0 }},
+				{"Peek", func() bool { _, ok := h.Peek(); return !ok }},
+				{"PopMin", func() bool { _, ok := h.PopMin(); return !ok }},
+				{"ExtractMin", func() bool { _, ok := h.ExtractMin(); return !ok }},
+				{"MinMax", func() bool { _, _, ok := h.MinMax(); return !ok }},
+				{"Remove", func() bool { return !h.Remove(1) }},
+				{"Update", func() bool { return !h.Update(1, 2) }},
+				{"IndexOf", func() bool { _, ok := h.IndexOf(1); return !ok }},
+				{"Count", func() bool { return h.Count(1) == 0 }},
+				{"RemoveSet", func() bool { return h.RemoveSet(map[int]struct{}{1: {}}) == 0 }},
+				{"PeekN", func() bool { return len(h.PeekN(3)) == 0 }},
+				{"Drain", func() bool { return len(h.Drain()) == 0 }},
+			}
+			for _, op := range ops {
+				if !op.run() {
+					t.Errorf("%s on an empty heap gave a non-empty result", op.name)
+				}
+				if len(h.items) != 0 || len(h.index) != 0 {
+					t.Fatalf("%s left items = %v, index = %v", op.name, h.items, h.index)
+				}
+			}
+
+			h.Insert(4)
+			checkHeapInvariant(t, h)
+			if got, ok := h.PopMin(); !ok || got != 4 {
+				t.Errorf("PopMin() after the empty-heap calls = %d, %t; want 4, true", got, ok)
+			}
+		})
+	}
+}
+
+func TestItemHeapToSortedSet(t *testing.T) {
+	rng := newTestRand(153)
+	for _, h := range []*ItemHeap{NewItemHeap(), newItemHeapWith(5, 1, 5, 3), NewMaxItemHeap()} {
+		for range rng.IntN(60) {
+			h.Insert(rng.IntN(40) - 20)
+		}
+		before := slices.Clone(h.items)
+		want := h.Clone().Drain()
+		slices.Sort(want) // Ascending even for the max-heap
+		want = slices.Compact(want)
+
+		s := h.ToSortedSet()
+		if !slices.Equal(h.items, before) {
+			t.Fatalf("ToSortedSet modified the heap: %v, want %v", h.items, before)
+		}
+		if got := s.Values(); !slices.Equal(got, want) || s.Len() != len(want) {
+			t.Fatalf("Values() = %v (Len %d), want %v", got, s.Len(), want)
+		}
+		var inOrder []int
+		s.InOrder(func(v int) bool { inOrder = append(inOrder, v); return true })
+		if !slices.Equal(inOrder, want) {
+			t.Errorf("InOrder visited %v, want %v", inOrder, want)
+		}
+
+		for x := -22; x <= 22; x++ {
+			rank, _ := slices.BinarySearch(want, x)
+			if got := s.Rank(x); got != rank {
+				t.Errorf("Rank(%d) = %d, want %d", x, got, rank)
+			}
+			floor, floorOK := 0, false
+			if i, found := slices.BinarySearch(want, x); found {
+				floor, floorOK = want[i], true
+			} else if i > 0 {
+				floor, floorOK = want[i-1], true
+			}
+			if got, ok := s.Floor(x); got != floor || ok != floorOK {
+				t.Errorf("Floor(%d) = %d, %t; want %d, %t", x, got, ok, floor, floorOK)
+			}
+			ceil, ceilOK := 0, rank < len(want)
+			if ceilOK {
+				ceil = want[rank]
+			}
+			if got, ok := s.Ceiling(x); got != ceil || ok != ceilOK {
+				t.Errorf("Ceiling(%d) = %d, %t; want %d, %t", x, got, ok, ceil, ceilOK)
+			}
+		}
+	}
+
+	h := newItemHeapWith(5, 1, 5, 3, 1, 5)
+	s := h.ToSortedSet()
+	if got, want := s.Values(), []int{1, 3, 5}; !slices.Equal(got, want) || s.Len() != 3 {
+		t.Errorf("Values() of a heap with duplicates = %v (Len %d), want %v: one entry per distinct value", got, s.Len(), want)
+	}
+	if h.Len() != 6 || h.Count(5) != 3 {
+		t.Errorf("after ToSortedSet Len() = %d, Count(5) = %d; want 6, 3", h.Len(), h.Count(5))
+	}
+}
+
+func TestItemHeapRemoveSet(t *testing.T) {
+	h := newItemHeapWith(9, 4, 7, 1, 4, 8, 2, 6, 3, 5)
+	removed := h.RemoveSet(map[int]struct{}{1: {}, 4: {}, 8: {}, 42: {}})
+	if removed != 4 { // 1, both copies of 4, and 8; 42 isn't present
+		t.Errorf("RemoveSet() = %d, want 4", removed)
+	}
+	checkHeapInvariant(t, h)
+	if got := h.GetMin(); got != 2 {
+		t.Errorf("GetMin() after RemoveSet = %d, want 2", got)
+	}
+	if got, want := h.Clone().Drain(), []int{2, 3, 5, 6, 7, 9}; !slices.Equal(got, want) {
+		t.Errorf("survivors = %v, want %v", got, want)
+	}
+
+	if got := h.RemoveSet(map[int]struct{}{100: {}}); got != 0 {
+		t.Errorf("RemoveSet(absent) = %d, want 0", got)
+	}
+	if got := h.RemoveSet(nil); got != 0 {
+		t.Errorf("RemoveSet(nil) = %d, want 0", got)
+	}
+	all := map[int]struct{}{2: {}, 3: {}, 5: {}, 6: {}, 7: {}, 9: {}}
+	if got := h.RemoveSet(all); got != 6 || h.Len() != 0 {
+		t.Errorf("RemoveSet(everything) = %d leaving Len() = %d, want 6 and 0", got, h.Len())
+	}
+	checkHeapInvariant(t, h)
+}
+
+func TestItemHeapOnMinChange(t *testing.T) {
+	type event struct {
+		min int
+		ok  bool
+	}
+	h := NewItemHeap()
+	var events []event
+	h.OnMinChange(func(newMin int, ok bool) { events = append(events, event{newMin, ok}) })
+
+	steps := []struct {
+		name string
+		op   func()
+		want []event // Events fired by this step alone
+	}{
+		{"first insert", func() { h.Insert(5) }, []event{{5, true}}},
+		{"larger insert", func() { h.Insert(8) }, nil},
+		{"new smallest", func() { h.Insert(3) }, []event{{3, true}}},
+		{"equal to min", func() { h.Insert(3) }, nil},
+		{"remove one min copy", func() { h.Remove(3) }, nil},
+		{"remove non-min", func() { h.Remove(8) }, nil},
+		{"remove absent", func() { h.Remove(99) }, nil},
+		{"update to new min", func() { h.Update(5, 1) }, []event{{1, true}}},
+		{"update non-min", func() { h.Update(3, 4) }, nil},
+		{"pop", func() { h.PopMin() }, []event{{4, true}}},
+		{"remove set", func() { h.RemoveSet(map[int]struct{}{7: {}}) }, nil},
+		{"merge smaller", func() { h.Merge(newItemHeapWith(2, 6)) }, []event{{2, true}}},
+		{"drain", func() { h.Drain() }, []event{{4, true}, {6, true}, {0, false}}},
+		{"pop empty", func() { h.PopMin() }, nil},
+	}
+	for _, step := range steps {
+		events = nil
+		step.op()
+		if !slices.Equal(events, step.want) {
+			t.Errorf("%s: fired %v, want %v", step.name, events, step.want)
+		}
+	}
+
+	h.OnMinChange(nil)
+	h.Insert(1) // Must not call the removed observer
+	if len(events) != 0 {
+		t.Errorf("removed observer still fired: %v", events)
+	}
+}
+
+func TestItemHeapIndexOfAndFix(t *testing.T) {
+	// Task ids ordered by a priority table the comparator captures.
+	priority := map[int]int{1: 50, 2: 20, 3: 30, 4: 40}
+	h := NewItemHeapFunc(func(a, b int) bool { return priority[a] < priority[b] })
+	for id := range 4 {
+		h.Insert(id + 1)
+	}
+	if got := h.GetMin(); got != 2 {
+		t.Fatalf("GetMin() = %d, want task 2", got)
+	}
+
+	for _, change := range []struct{ id, priority, wantMin int }{
+		{4, 10, 4}, // Moves up to the root
+		{4, 60, 2}, // Moves back down
+		{1, 5, 1},
+		{3, 1, 3},
+	} {
+		priority[change.id] = change.priority
+		i, ok := h.IndexOf(change.id)
+		if !ok || h.items[i] != change.id {
+			t.Fatalf("IndexOf(%d) = %d, %t", change.id, i, ok)
+		}
+		h.Fix(i)
+		checkHeapInvariant(t, h)
+		if got := h.GetMin(); got != change.wantMin {
+			t.Errorf("after setting task %d to %d, GetMin() = %d, want %d", change.id, change.priority, got, change.wantMin)
+		}
+	}
+
+	if _, ok := h.IndexOf(99); ok {
+		t.Error("IndexOf(99) ok = true for a value not in the heap")
+	}
+}
+
+func TestItemHeapRebuildAfterExternalEdits(t *testing.T) {
+	rng := newTestRand(159)
+	h := newItemHeapWith(randomInts(rng, 40, 30)...)
+
+	// Scramble the backing slice and overwrite some values behind the heap's back.
+	rng.Shuffle(len(h.items), func(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] })
+	h.items[0], h.items[7] = 99, -3
+	h.items = append(h.items, 12, 12)
+	want := slices.Sorted(slices.Values(h.items))
+
+	h.Rebuild()
+	checkHeapInvariant(t, h)
+	if got := h.GetMin(); got != -3 {
+		t.Errorf("GetMin() after Rebuild = %d, want -3", got)
+	}
+	if h.Count(99) != 1 || h.Count(12) < 2 {
+		t.Errorf("Count(99) = %d, Count(12) = %d after Rebuild", h.Count(99), h.Count(12))
+	}
+	if !h.Remove(99) {
+		t.Error("Remove(99) after Rebuild = false, want true")
+	}
+	want = slices.DeleteFunc(want, func(x int) bool { return x == 99 })
+	if got := h.Drain(); !slices.Equal(got, want) {
+		t.Errorf("Drain() after Rebuild = %v, want %v", got, want)
+	}
+}
+
+func TestItemHeapMinMax(t *testing.T) {
+	rng := newTestRand(160)
+	for range 50 {
+		values := randomInts(rng, 1+rng.IntN(40), 1000)
+		lo, hi := slices.Min(values), slices.Max(values)
+
+		minHeap, maxHeap := newItemHeapWith(values...), NewMaxItemHeap()
+		for _, x := range values {
+			maxHeap.Insert(x)
+		}
+		if gotMin, gotMax, ok := minHeap.MinMax(); !ok || gotMin != lo || gotMax != hi {
+			t.Fatalf("min-heap MinMax() over %v = %d, %d, %t; want %d, %d, true", values, gotMin, gotMax, ok, lo, hi)
+		}
+		// For a max-heap the "min" is its top, the largest value.
+		if gotTop, gotBottom, ok := maxHeap.MinMax(); !ok || gotTop != hi || gotBottom != lo {
+			t.Fatalf("max-heap MinMax() over %v = %d, %d, %t; want %d, %d, true", values, gotTop, gotBottom, ok, hi, lo)
+		}
+	}
+}
+
+// heapAlloc returns the live heap size after a full garbage collection.
+func heapAlloc() uint64 {
+	var m runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+// TestItemHeapIndexShrinksAfterGrowth grows a heap to many distinct values,
+// pops almost all of them, and checks that the index map's memory was handed
+// back while the heap kept working.
+func TestItemHeapIndexShrinksAfterGrowth(t *testing.T) {
+	const n = 200_000
+	base := heapAlloc()
+	h := NewItemHeap()
+	for x := n; x > 0; x-- {
+		h.Insert(x)
+	}
+	grown := heapAlloc() - base
+
+	for h.Len() > 10 {
+		h.PopMin()
+	}
+	checkHeapInvariant(t, h)
+	if h.indexPeak >= indexCompactMin {
+		t.Errorf("indexPeak = %d with %d entries, want the index compacted below %d", h.indexPeak, len(h.index), indexCompactMin)
+	}
+	// items keeps its capacity; what is handed back is the index map.
+	if after := heapAlloc(); after > base && (after-base)*3 > grown {
+		t.Errorf("heap retains %d of the %d bytes it grew by, want under a third", after-base, grown)
+	}
+
+	if got := h.GetMin(); got != n-9 {
+		t.Errorf("GetMin() = %d, want %d", got, n-9)
+	}
+	if !h.Remove(n) || h.Remove(n) || h.Len() != 9 {
+		t.Error("Remove after compaction misbehaved")
+	}
+	runtime.KeepAlive(h)
+}
+
+// TestMaxItemHeap runs random inserts, removes and updates on a max-heap and
+// checks after each that GetMin and Peek report the largest remaining value.
+func TestMaxItemHeap(t *testing.T) {
+	rng := newTestRand(272)
+	h := NewMaxItemHeap()
+	var model []int
+	for step := range 2000 {
+		switch op := rng.IntN(4); {
+		case op < 2 || len(model) == 0:
+			x := rng.IntN(100)
+			h.Insert(x)
+			model = append(model, x)
+		case op == 2:
+			i := rng.IntN(len(model))
+			if !h.Remove(model[i]) {
+				t.Fatalf("step %d: Remove(%d) = false", step, model[i])
+			}
+			model = slices.Delete(model, i, i+1)
+		default:
+			i, x := rng.IntN(len(model)), rng.IntN(100)
+			if !h.Update(model[i], x) {
+				t.Fatalf("step %d: Update(%d, %d) = false", step, model[i], x)
+			}
+			model[i] = x
+		}
+		checkHeapInvariant(t, h)
+		if got, ok := h.Peek(); !ok || got != slices.Max(model) || h.GetMin() != got {
+			t.Fatalf("step %d: Peek() = %d, %t, want the maximum %d", step, got, ok, slices.Max(model))
+		}
+	}
+
+	want := slices.Sorted(slices.Values(model))
+	slices.Reverse(want)
+	if got := h.Drain(); !slices.Equal(got, want) {
+		t.Errorf("Drain() = %v, want %v (largest first)", got, want)
+	}
+}
+
+func TestItemHeapRemoveOneDuplicateAtATime(t *testing.T) {
+	h := newItemHeapWith(7, 5, 2, 5, 9, 5)
+	for i := range 2 {
+		if !h.Remove(5) {
+			t.Fatalf("Remove(5) #%d = false", i+1)
+		}
+		checkHeapInvariant(t, h)
+	}
+	if got := h.Len(); got != 4 {
+		t.Errorf("Len() = %d, want 4", got)
+	}
+	if got := h.Count(5); got != 1 {
+		t.Fatalf("Count(5) = %d, want 1", got)
+	}
+	if i, ok := h.IndexOf(5); !ok || h.items[i] != 5 {
+		t.Errorf("IndexOf(5) = %d, %t; that slot holds %v", i, ok, h.items)
+	}
+	if got := h.Drain(); !slices.Equal(got, []int{2, 5, 7, 9}) {
+		t.Errorf("Drain() = %v, want [2 5 7 9]", got)
+	}
+}
+
+func TestItemHeapTopK(t *testing.T) {
+	values := []int{42, 7, 19, 7, 3, 88, 61, 25}
+	for _, tt := range []struct {
+		name string
+		new  func() *ItemHeap
+		want []int // All values in pop order
+	}{
+		{"min", NewItemHeap, []int{3, 7, 7, 19, 25, 42, 61, 88}},
+		{"max", NewMaxItemHeap, []int{88, 61, 42, 25, 19, 7, 7, 3}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := tt.new()
+			for _, x := range values {
+				h.Insert(x)
+			}
+			before := slices.Clone(h.items)
+			for _, k := range []int{1, 3, len(values), len(values) + 10} {
+				if got, want := h.TopK(k), tt.want[:min(k, len(values))]; !slices.Equal(got, want) {
+					t.Errorf("TopK(%d) = %v, want %v", k, got, want)
+				}
+			}
+			if got := h.TopK(0); got == nil || len(got) != 0 {
+				t.Errorf("TopK(0) = %#v, want []int{}", got)
+			}
+			if !slices.Equal(h.items, before) {
+				t.Fatalf("TopK modified the heap: %v, want %v", h.items, before)
+			}
+			checkHeapInvariant(t, h)
+			if got := h.Drain(); !slices.Equal(got, tt.want) {
+				t.Errorf("Drain() after TopK = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestItemHeapUpdate(t *testing.T) {
+	h := newItemHeapWith(10, 20, 30, 40, 50)
+
+	steps := []struct {
+		old, new int
+		ok       bool
+		min      int
+	}{
+		{40, 5, true, 5},   // Downward, becomes the new min
+		{5, 45, true, 10},  // Upward, gives the min back
+		{10, 10, true, 10}, // No-op
+		{20, 1, true, 1},
+		{99, 0, false, 1}, // Not present
+		{1, 30, true, 10}, // Now a duplicate of 30
+	}
+	for _, s := range steps {
+		if got := h.Update(s.old, s.new); got != s.ok {
+			t.Errorf("Update(%d, %d) = %t, want %t", s.old, s.new, got, s.ok)
+		}
+		checkHeapInvariant(t, h)
+		if got := h.GetMin(); got != s.min {
+			t.Errorf("GetMin() after Update(%d, %d) = %d, want %d", s.old, s.new, got, s.min)
+		}
+		if s.ok && s.old != s.new && h.Count(s.old) != 0 {
+			t.Errorf("Count(%d) after Update = %d, want 0", s.old, h.Count(s.old))
+		}
+	}
+	if got := h.Count(30); got != 2 {
+		t.Errorf("Count(30) = %d, want 2", got)
+	}
+	if got := h.Drain(); !slices.Equal(got, []int{10, 30, 30, 45, 50}) {
+		t.Errorf("Drain() = %v, want [10 30 30 45 50]", got)
+	}
+}
+
+func TestItemHeapMerge(t *testing.T) {
+	h := newItemHeapWith(8, 3, 12, 5)
+	other := newItemHeapWith(9, 1, 5, 20)
+	otherBefore := slices.Clone(other.items)
+
+	h.Merge(other)
+	checkHeapInvariant(t, h)
+	if got := h.Len(); got != 8 {
+		t.Errorf("Len() after Merge = %d, want 8", got)
+	}
+	if got := h.GetMin(); got != 1 {
+		t.Errorf("GetMin() after Merge = %d, want 1", got)
+	}
+	if got := h.Count(5); got != 2 {
+		t.Errorf("Count(5) after Merge = %d, want 2 (duplicates are kept)", got)
+	}
+	if !slices.Equal(other.items, otherBefore) {
+		t.Errorf("Merge modified other: %v, want %v", other.items, otherBefore)
+	}
+	checkHeapInvariant(t, other)
+
+	// Every merged value is in the rebuilt index, so Remove can find it.
+	for _, x := range []int{20, 1, 5} {
+		if !h.Remove(x) {
+			t.Errorf("Remove(%d) after Merge = false", x)
+		}
+	}
+	if got := h.Drain(); !slices.Equal(got, []int{3, 5, 8, 9, 12}) {
+		t.Errorf("Drain() = %v, want [3 5 8 9 12]", got)
+	}
+
+	empty := NewItemHeap()
+	empty.Merge(NewItemHeap())
+	empty.Merge(other)
+	if got := empty.Drain(); !slices.Equal(got, []int{1, 5, 9, 20}) {
+		t.Errorf("merging into an empty heap drained %v, want [1 5 9 20]", got)
+	}
+}
+
+func TestItemHeapExtractMinDrainsSorted(t *testing.T) {
+	values := randomInts(newTestRand(278), 300, 50)
+	h := newItemHeapWith(values...)
+	want := slices.Sorted(slices.Values(values))
+
+	for i, w := range want {
+		peeked := h.GetMin()
+		got, ok := h.ExtractMin()
+		if !ok || got != w || got != peeked {
+			t.Fatalf("ExtractMin() #%d = %d, %t; want %d (GetMin said %d)", i, got, ok, w, peeked)
+		}
+		if h.Len() != len(want)-i-1 {
+			t.Fatalf("Len() after %d ExtractMin calls = %d", i+1, h.Len())
+		}
+		if i%25 == 0 {
+			checkHeapInvariant(t, h)
+		}
+	}
+	if got, ok := h.ExtractMin(); ok || got != 0 {
+		t.Errorf("ExtractMin() on an empty heap = %d, %t; want 0, false", got, ok)
+	}
+	if len(h.index) != 0 {
+		t.Errorf("index still holds %d entries after draining", len(h.index))
+	}
+}
+
+// TestNewItemHeapFromSlice heapifies random slices and compares the result
+// with sorting them, then checks the heap doesn't share the caller's slice.
+func TestNewItemHeapFromSlice(t *testing.T) {
+	rng := newTestRand(279)
+	for _, n := range []int{0, 1, 2, 7, 64, 500} {
+		items := randomInts(rng, n, 100)
+		h := NewItemHeapFromSlice(items)
+		checkHeapInvariant(t, h)
+		if h.Len() != n {
+			t.Fatalf("n=%d: Len() = %d", n, h.Len())
+		}
+		if got, ok := h.Peek(); n > 0 && (!ok || got != slices.Min(items)) {
+			t.Fatalf("n=%d: Peek() = %d, %t; want the slice minimum %d", n, got, ok, slices.Min(items))
+		}
+
+		original := slices.Clone(items)
+		for i := range items {
+			items[i] = -1 // Must not reach the heap
+		}
+		h.Insert(50)
+		if got, want := h.Drain(), slices.Sorted(slices.Values(append(original, 50))); !slices.Equal(got, want) {
+			t.Fatalf("n=%d: Drain() = %v, want %v", n, got, want)
+		}
+		if slices.ContainsFunc(items, func(x int) bool { return x != -1 }) {
+			t.Fatalf("n=%d: heap operations wrote to the caller's slice: %v", n, items)
+		}
+	}
+
+	if h := NewItemHeapFromSlice(nil); h.Len() != 0 || h.items == nil {
+		t.Errorf("NewItemHeapFromSlice(nil) = %v, want an empty, non-nil heap", h.items)
+	}
+}
+
+// BenchmarkItemHeapDrainDuplicates drains a heap holding only a handful of
+// distinct values, so every Swap and Pop touches a long slot list.
+func BenchmarkItemHeapDrainDuplicates(b *testing.B) {
+	rng := newTestRand(273)
+	items := randomInts(rng, 40000, 4)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h := NewItemHeapFromSlice(items)
+		for h.Len() > 0 {
+			h.PopMin()
+		}
+	}
+}
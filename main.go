@@ -1,21 +1,42 @@
 package main
 
 import (
-  "fmt"
+	"fmt"
+	"os"
+	"sort"
 )
 
-//TIP <p>To run your code, right-click the code and select <b>Run</b>.</p> <p>Alternatively, click
-// the <icon src="AllIcons.Actions.Execute"/> icon in the gutter and select the <b>Run</b> menu item from here.</p>
+// demos maps a command-line argument to the example it runs, so each data
+// structure's walkthrough can be started with "go run . <name>".
+var demos = map[string]func(){
+	"trie":     trieDemo,
+	"intheap":  intHeapDemo,
+	"itemheap": itemHeapDemo,
+	"pq":       priorityQueueDemo,
+}
 
 func main() {
-  //TIP <p>Press <shortcut actionId="ShowIntentionActions"/> when your caret is at the underlined text
-  // to see how GoLand suggests fixing the warning.</p><p>Alternatively, if available, click the lightbulb to view possible fixes.</p>
-  s := "gopher"
-  fmt.Println("Hello and welcome, %s!", s)
+	if len(os.Args) > 1 {
+		demo, ok := demos[os.Args[1]]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown demo %q (available: %v)\n", os.Args[1], demoNames())
+			os.Exit(2)
+		}
+		demo()
+		return
+	}
 
-  for i := 1; i <= 5; i++ {
-	//TIP <p>To start your debugging session, right-click your code in the editor and select the Debug option.</p> <p>We have set one <icon src="AllIcons.Debugger.Db_set_breakpoint"/> breakpoint
-	// for you, but you can always add more by pressing <shortcut actionId="ToggleLineBreakpoint"/>.</p>
-	fmt.Println("i =", 100/i)
-  }
-}
\ No newline at end of file
+	s := "gopher"
+	fmt.Printf("Hello and welcome, %s!\n", s)
+	fmt.Println("Run one of the demos with: go run .", demoNames())
+}
+
+// demoNames returns the registered demo names in sorted order.
+func demoNames() []string {
+	names := make([]string, 0, len(demos))
+	for name := range demos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"cmp"
+	"container/heap"
+	"fmt"
+)
+
+// PQItem is the handle Push returns for an entry of a PriorityQueue.
+// Entries are tracked by handle rather than by key or value, so several
+// entries may share a key (or a value).
+type PQItem[K cmp.Ordered, V any] struct {
+	key   K
+	value V
+	index int // Position in the queue's slice; -1 once removed
+}
+
+// Key returns the entry's current priority.
+func (it *PQItem[K, V]) Key() K { return it.key }
+
+// Value returns the entry's payload. Unlike PriorityQueue.Peek it never
+// clones, so for a payload holding slices, maps or pointers the result shares
+// that memory with the queued entry.
+func (it *PQItem[K, V]) Value() V { return it.value }
+
+// pqEntries implements heap.Interface over PQItem handles, keeping each
+// handle's index in sync as entries move.
+type pqEntries[K cmp.Ordered, V any] []*PQItem[K, V]
+
+func (e pqEntries[K, V]) Len() int           { return len(e) }
+func (e pqEntries[K, V]) Less(i, j int) bool { return e[i].key < e[j].key }
+func (e pqEntries[K, V]) Swap(i, j int) {
+	e[i], e[j] = e[j], e[i]
+	e[i].index = i
+	e[j].index = j
+}
+
+func (e *pqEntries[K, V]) Push(x any) {
+	item := x.(*PQItem[K, V])
+	item.index = len(*e)
+	*e = append(*e, item)
+}
+
+func (e *pqEntries[K, V]) Pop() any {
+	old := *e
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil // Don't keep the popped entry reachable
+	item.index = -1
+	*e = old[:n-1]
+	return item
+}
+
+// PriorityQueue is a min-priority queue ordered by K where each entry carries
+// an arbitrary payload V.
+type PriorityQueue[K cmp.Ordered, V any] struct {
+	entries pqEntries[K, V]
+	clone   func(V) V // Deep-copies payloads handed out by Peek; nil for plain copies
+}
+
+// NewPriorityQueue creates an empty PriorityQueue.
+func NewPriorityQueue[K cmp.Ordered, V any]() *PriorityQueue[K, V] {
+	return &PriorityQueue[K, V]{}
+}
+
+// NewPriorityQueueClone creates an empty PriorityQueue whose Peek returns
+// clone(payload) rather than the payload itself. Use it when V contains
+// slices, maps or pointers, so a caller mutating what Peek returned can't
+// change the entry still sitting in the queue.
+func NewPriorityQueueClone[K cmp.Ordered, V any](clone func(V) V) *PriorityQueue[K, V] {
+	return &PriorityQueue[K, V]{clone: clone}
+}
+
+// Len returns the number of queued entries.
+func (pq *PriorityQueue[K, V]) Len() int {
+	return pq.entries.Len()
+}
+
+// Push adds value with priority key and returns the handle to Update or Remove it later.
+func (pq *PriorityQueue[K, V]) Push(key K, value V) *PQItem[K, V] {
+	item := &PQItem[K, V]{key: key, value: value}
+	heap.Push(&pq.entries, item)
+	return item
+}
+
+// Peek returns the entry with the smallest key without removing it.
+// ok is false when the queue is empty.
+//
+// The payload is returned by value, which is only a shallow copy: if V holds
+// slices, maps or pointers, mutating them through the result also mutates
+// the queued entry unless the queue was built with NewPriorityQueueClone.
+// PopMin has no such caveat, since the queue lets go of the entry.
+func (pq *PriorityQueue[K, V]) Peek() (key K, value V, ok bool) {
+	if len(pq.entries) == 0 {
+		return key, value, false
+	}
+	value = pq.entries[0].value
+	if pq.clone != nil {
+		value = pq.clone(value)
+	}
+	return pq.entries[0].key, value, true
+}
+
+// PopMin removes and returns the entry with the smallest key.
+// ok is false when the queue is empty.
+func (pq *PriorityQueue[K, V]) PopMin() (key K, value V, ok bool) {
+	if len(pq.entries) == 0 {
+		return key, value, false
+	}
+	item := heap.Pop(&pq.entries).(*PQItem[K, V])
+	return item.key, item.value, true
+}
+
+// Update changes the priority of the entry behind item and restores heap order.
+// It returns false if the entry is no longer in this queue.
+func (pq *PriorityQueue[K, V]) Update(item *PQItem[K, V], newKey K) bool {
+	if !pq.owns(item) {
+		return false
+	}
+	item.key = newKey
+	heap.Fix(&pq.entries, item.index)
+	return true
+}
+
+// Remove deletes the entry behind item, reporting whether it was still queued.
+func (pq *PriorityQueue[K, V]) Remove(item *PQItem[K, V]) bool {
+	if !pq.owns(item) {
+		return false
+	}
+	heap.Remove(&pq.entries, item.index)
+	return true
+}
+
+// owns reports whether item is currently an entry of pq.
+func (pq *PriorityQueue[K, V]) owns(item *PQItem[K, V]) bool {
+	return item != nil && item.index >= 0 && item.index < len(pq.entries) && pq.entries[item.index] == item
+}
+
+// This example schedules tasks by priority and bumps one of them forward.
+// Run it with "go run . pq".
+func priorityQueueDemo() {
+	pq := NewPriorityQueue[int, string]()
+	pq.Push(3, "write docs")
+	review := pq.Push(5, "review PR")
+	pq.Push(1, "fix build")
+	pq.Push(3, "reply to issue")
+
+	pq.Update(review, 0) // Suddenly urgent
+
+	for pq.Len() > 0 {
+		priority, task, _ := pq.PopMin()
+		fmt.Printf("%d: %s\n", priority, task)
+	}
+}
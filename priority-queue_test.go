@@ -0,0 +1,136 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPriorityQueueSchedulesTasks(t *testing.T) {
+	pq := NewPriorityQueue[int, string]()
+	if _, _, ok := pq.Peek(); ok {
+		t.Error("Peek() on an empty queue ok = true")
+	}
+
+	handles := map[string]*PQItem[int, string]{}
+	for _, task := range []struct {
+		priority int
+		name     string
+	}{
+		{4, "deploy"}, {2, "test"}, {2, "lint"}, {7, "docs"}, {1, "build"}, {5, "release"},
+	} {
+		handles[task.name] = pq.Push(task.priority, task.name)
+	}
+	if key, value, ok := pq.Peek(); !ok || key != 1 || value != "build" || pq.Len() != 6 {
+		t.Fatalf("Peek() = %d, %q, %t with Len() %d; want 1, build, true, 6", key, value, ok, pq.Len())
+	}
+
+	if !pq.Update(handles["docs"], 0) { // Docs become urgent
+		t.Fatal("Update(docs, 0) = false")
+	}
+	if !pq.Update(handles["build"], 6) || handles["build"].Key() != 6 {
+		t.Fatal("Update(build, 6) did not change its key")
+	}
+	if !pq.Remove(handles["release"]) || pq.Remove(handles["release"]) {
+		t.Fatal("Remove(release) should succeed exactly once")
+	}
+
+	var order []string
+	var keys []int
+	for pq.Len() > 0 {
+		key, value, _ := pq.PopMin()
+		order = append(order, value)
+		keys = append(keys, key)
+	}
+	if !slices.IsSorted(keys) {
+		t.Errorf("PopMin keys = %v, want ascending", keys)
+	}
+	if order[0] != "docs" || order[len(order)-1] != "build" || len(order) != 5 {
+		t.Errorf("PopMin order = %q, want docs first, build last, 5 tasks", order)
+	}
+	if _, _, ok := pq.PopMin(); ok {
+		t.Error("PopMin() on an empty queue ok = true")
+	}
+
+	// Handles of popped entries no longer belong to the queue.
+	if pq.Update(handles["docs"], 3) || pq.Remove(handles["test"]) {
+		t.Error("Update/Remove accepted a handle whose entry was already popped")
+	}
+	other := NewPriorityQueue[int, string]()
+	h := other.Push(1, "x")
+	pq.Push(1, "y")
+	if pq.Update(h, 0) || pq.Remove(h) || pq.Remove(nil) {
+		t.Error("Update/Remove accepted a handle from another queue")
+	}
+}
+
+// TestPriorityQueueRandomOps cross-checks random pushes, updates, removals
+// and pops against a slice of live entries.
+func TestPriorityQueueRandomOps(t *testing.T) {
+	rng := newTestRand(158)
+	pq := NewPriorityQueue[int, int]()
+	var live []*PQItem[int, int]
+	for step := range 3000 {
+		switch op := rng.IntN(4); {
+		case op == 0 || len(live) == 0:
+			live = append(live, pq.Push(rng.IntN(50), step))
+		case op == 1:
+			item := live[rng.IntN(len(live))]
+			pq.Update(item, rng.IntN(50))
+		case op == 2:
+			i := rng.IntN(len(live))
+			pq.Remove(live[i])
+			live = slices.Delete(live, i, i+1)
+		default:
+			key, value, _ := pq.PopMin()
+			i := slices.IndexFunc(live, func(it *PQItem[int, int]) bool { return it.Value() == value })
+			if i < 0 || live[i].Key() != key {
+				t.Fatalf("step %d: PopMin() = %d, %d which is not a live entry", step, key, value)
+			}
+			for _, it := range live {
+				if it.Key() < key {
+					t.Fatalf("step %d: PopMin() = %d but %d is still queued", step, key, it.Key())
+				}
+			}
+			live = slices.Delete(live, i, i+1)
+		}
+		if pq.Len() != len(live) {
+			t.Fatalf("step %d: Len() = %d, want %d", step, pq.Len(), len(live))
+		}
+	}
+}
+
+type job struct {
+	name string
+	tags []string
+}
+
+func TestPriorityQueuePeekIsCopySafe(t *testing.T) {
+	pq := NewPriorityQueueClone[int](func(j job) job {
+		j.tags = slices.Clone(j.tags)
+		return j
+	})
+	pq.Push(1, job{name: "build", tags: []string{"ci"}})
+	pq.Push(2, job{name: "deploy", tags: []string{"prod"}})
+
+	_, peeked, _ := pq.Peek()
+	peeked.tags[0] = "mutated"
+	peeked.tags = append(peeked.tags, "extra")
+	peeked.name = "renamed"
+
+	_, again, _ := pq.Peek()
+	if again.name != "build" || !slices.Equal(again.tags, []string{"ci"}) {
+		t.Errorf("queued entry changed through Peek's result: %+v", again)
+	}
+	if _, popped, _ := pq.PopMin(); !slices.Equal(popped.tags, []string{"ci"}) {
+		t.Errorf("PopMin() tags = %q, want [ci]", popped.tags)
+	}
+
+	// Without a clone function, Peek's copy is shallow, as documented.
+	shallow := NewPriorityQueue[int, job]()
+	shallow.Push(1, job{name: "build", tags: []string{"ci"}})
+	_, peeked, _ = shallow.Peek()
+	peeked.tags[0] = "mutated"
+	if _, got, _ := shallow.Peek(); got.tags[0] != "mutated" {
+		t.Errorf("plain Peek deep-copied the payload: %+v", got)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+// Helpers shared by the Trie and heap tests. They live in a _test.go file of
+// the package itself rather than in an internal/testutil package because
+// checkHeapInvariant and checkTrieInvariant need the unexported fields, and
+// a separate package cannot import package main.
+
+import (
+	"bufio"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// loadWords reads testdata/name, one word per line, skipping blank lines and
+// lines starting with '#'.
+func loadWords(t testing.TB, name string) []string {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("loadWords: %v", err)
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("loadWords: reading %s: %v", name, err)
+	}
+	return words
+}
+
+// newTestRand returns a deterministic generator, so a failing randomized test
+// fails the same way on every run.
+func newTestRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+}
+
+// randomWord returns a word of minLen to maxLen lowercase letters drawn from
+// the first alphabet letters ('a', 'b', ...). A small alphabet makes shared
+// prefixes, and therefore the interesting Trie paths, common.
+func randomWord(rng *rand.Rand, minLen, maxLen, alphabet int) string {
+	b := make([]byte, minLen+rng.IntN(maxLen-minLen+1))
+	for i := range b {
+		b[i] = byte('a' + rng.IntN(alphabet))
+	}
+	return string(b)
+}
+
+// randomWords returns n words from randomWord, repeats allowed.
+func randomWords(rng *rand.Rand, n, minLen, maxLen, alphabet int) []string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = randomWord(rng, minLen, maxLen, alphabet)
+	}
+	return words
+}
+
+// randomInts returns n values drawn uniformly from [0, limit), repeats allowed.
+func randomInts(rng *rand.Rand, n, limit int) []int {
+	values := make([]int, n)
+	for i := range values {
+		values[i] = rng.IntN(limit)
+	}
+	return values
+}
+
+// checkHeapInvariant fails the test unless h is heap-ordered under its
+// comparator and its index map lists exactly the slots holding each value.
+func checkHeapInvariant(t testing.TB, h *ItemHeap) {
+	t.Helper()
+	for i := 1; i < len(h.items); i++ {
+		if parent := (i - 1) / 2; h.less(h.items[i], h.items[parent]) {
+			t.Fatalf("heap order violated: items[%d]=%d sorts before its parent items[%d]=%d (items %v)",
+				i, h.items[i], parent, h.items[parent], h.items)
+		}
+	}
+	slots := 0
+	seen := make(map[int]bool, len(h.items))
+	for x, xs := range h.index {
+		if len(xs) == 0 {
+			t.Fatalf("index[%d] is empty but still present", x)
+		}
+		for k, i := range xs {
+			if i < 0 || i >= len(h.items) || h.items[i] != x {
+				t.Fatalf("index[%d] lists slot %d, which doesn't hold it (items %v)", x, i, h.items)
+			}
+			if i >= len(h.at) || h.at[i] != k {
+				t.Fatalf("index[%d][%d] = %d, but at[%d] doesn't point back to it (at %v)", x, k, i, i, h.at)
+			}
+			if seen[i] {
+				t.Fatalf("slot %d is listed twice in the index", i)
+			}
+			seen[i] = true
+		}
+		slots += len(xs)
+	}
+	if slots != len(h.items) || len(h.at) != len(h.items) {
+		t.Fatalf("index covers %d slots and at has %d entries, heap has %d items", slots, len(h.at), len(h.items))
+	}
+}
+
+// checkTrieInvariant fails the test unless trie's bookkeeping agrees with its
+// contents: every node ends a word exactly when its count is positive, every
+// wordCount matches the words below it, Words is strictly sorted, and each
+// listed word is found by Search. Unlike Validate it accepts the dead nodes
+// soft Delete leaves behind; call Validate as well where none may exist.
+func checkTrieInvariant(t testing.TB, trie *Trie) {
+	t.Helper()
+	var walk func(node *Node, path string) int
+	walk = func(node *Node, path string) int {
+		if node.isEndOfWord != (node.count > 0) {
+			t.Fatalf("node at %q has isEndOfWord=%t but count=%d", path, node.isEndOfWord, node.count)
+		}
+		words := 0
+		if node.isEndOfWord {
+			words = 1
+		}
+		for i, child := range node.children {
+			if child != nil {
+				words += walk(child, path+string(indexToChar(i)))
+			}
+		}
+		if node.wordCount != words {
+			t.Fatalf("node at %q has wordCount=%d but %d words below it", path, node.wordCount, words)
+		}
+		return words
+	}
+	total := walk(trie.root, "")
+
+	words := trie.Words()
+	if len(words) != total || trie.CountWords() != total {
+		t.Fatalf("Words() has %d entries, CountWords() = %d, the nodes hold %d words",
+			len(words), trie.CountWords(), total)
+	}
+	if !slices.IsSorted(words) || len(slices.Compact(slices.Clone(words))) != len(words) {
+		t.Fatalf("Words() is not strictly increasing: %q", words)
+	}
+	for _, word := range words {
+		if !trie.Search(word) {
+			t.Fatalf("Search(%q) = false for a word listed by Words()", word)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Binary layout (all integers are unsigned varints unless noted):
+//
+//	header: magic "TRIE" (4 bytes), version (1 byte)
+//	node:   flags (1 byte, bit 0 = end of word)
+//	        count                  -- only if end of word
+//	        child count (1 byte)
+//	        per child, in index order:
+//	          char (1 byte), subtree length, subtree node
+//
+// Storing each subtree's length lets a reader skip siblings without decoding
+// them, which is what MappedTrie-style readers rely on.
+
+const (
+	binaryMagic   = "TRIE"
+	binaryVersion = 1
+
+	flagEndOfWord = 1 << 0
+)
+
+// ErrInvalidBinary is returned (wrapped) by ReadBinary for malformed input,
+// including truncated streams.
+var ErrInvalidBinary = errors.New("trie: invalid binary encoding")
+
+// WriteBinary writes the Trie, including insertion counts, in the compact
+// binary format read by ReadBinary. Branches left behind by soft deletes
+// lead to no word and are not written.
+func (t *Trie) WriteBinary(w io.Writer) error {
+	buf := append([]byte(binaryMagic), binaryVersion)
+	buf = append(buf, encodeNode(t.root)...)
+	_, err := w.Write(buf)
+	return err
+}
+
+// encodeNode returns the binary encoding of node and its subtree.
+func encodeNode(node *Node) []byte {
+	var buf []byte
+	if node.isEndOfWord {
+		buf = append(buf, flagEndOfWord)
+		buf = binary.AppendUvarint(buf, uint64(node.count))
+	} else {
+		buf = append(buf, 0)
+	}
+
+	childCount := 0
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil && child.wordCount > 0 {
+			childCount++
+		}
+	}
+	buf = append(buf, byte(childCount))
+
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil && child.wordCount > 0 {
+			sub := encodeNode(child)
+			buf = append(buf, indexToChar(i))
+			buf = binary.AppendUvarint(buf, uint64(len(sub)))
+			buf = append(buf, sub...)
+		}
+	}
+	return buf
+}
+
+// ReadBinary reconstructs a Trie written by WriteBinary. Malformed or truncated
+// input yields an error wrapping ErrInvalidBinary rather than a panic.
+func ReadBinary(r io.Reader) (*Trie, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < len(binaryMagic)+1 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return nil, fmt.Errorf("%w: missing %q header", ErrInvalidBinary, binaryMagic)
+	}
+	if v := data[len(binaryMagic)]; v != binaryVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidBinary, v)
+	}
+
+	body := data[len(binaryMagic)+1:]
+	root, n, err := decodeNode(body)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(body) {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidBinary, len(body)-n)
+	}
+	return &Trie{root: root}, nil
+}
+
+// decodeNode decodes one node and its subtree from the start of data,
+// returning the node and the number of bytes consumed.
+func decodeNode(data []byte) (*Node, int, error) {
+	node := NewNode()
+	pos := 0
+
+	if pos >= len(data) {
+		return nil, 0, fmt.Errorf("%w: truncated node", ErrInvalidBinary)
+	}
+	flags := data[pos]
+	pos++
+	if flags&flagEndOfWord != 0 {
+		count, n := binary.Uvarint(data[pos:])
+		if n <= 0 || count == 0 {
+			return nil, 0, fmt.Errorf("%w: bad word count", ErrInvalidBinary)
+		}
+		pos += n
+		node.isEndOfWord = true
+		node.count = int(count)
+		node.wordCount = 1
+	}
+
+	if pos >= len(data) {
+		return nil, 0, fmt.Errorf("%w: truncated node", ErrInvalidBinary)
+	}
+	childCount := int(data[pos])
+	pos++
+
+	for c := 0; c < childCount; c++ {
+		if pos >= len(data) {
+			return nil, 0, fmt.Errorf("%w: truncated child list", ErrInvalidBinary)
+		}
+		idx, ok := tryCharToIndex(data[pos])
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: invalid character %q", ErrInvalidBinary, data[pos])
+		}
+		if node.children[idx] != nil {
+			return nil, 0, fmt.Errorf("%w: duplicate child %q", ErrInvalidBinary, data[pos])
+		}
+		pos++
+
+		size, n := binary.Uvarint(data[pos:])
+		if n <= 0 || size > uint64(len(data)-pos-n) {
+			return nil, 0, fmt.Errorf("%w: truncated subtree", ErrInvalidBinary)
+		}
+		pos += n
+
+		child, used, err := decodeNode(data[pos : pos+int(size)])
+		if err != nil {
+			return nil, 0, err
+		}
+		if used != int(size) {
+			return nil, 0, fmt.Errorf("%w: subtree length mismatch", ErrInvalidBinary)
+		}
+		pos += used
+
+		node.children[idx] = child
+		node.wordCount += child.wordCount
+	}
+	return node, pos, nil
+}
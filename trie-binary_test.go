@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// encodeTrie returns trie in the WriteBinary format.
+func encodeTrie(t testing.TB, trie *Trie) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := trie.WriteBinary(&buf); err != nil {
+		t.Fatalf("WriteBinary() = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	rng := newTestRand(136)
+	tries := map[string]*Trie{
+		"empty":       NewTrie(),
+		"empty word":  newTrieWith(""),
+		"sample":      newTrieWith(sampleWords...),
+		"word list":   newTrieWith(loadWords(t, "words.txt")...),
+		"random":      newTrieWith(randomWords(rng, 300, 0, 8, 5)...),
+		"soft delete": newTrieWith("app", "apple", "application", "app"),
+	}
+	tries["soft delete"].Delete("application")
+	tries["random"].Insert("abc")
+	tries["random"].Insert("abc")
+
+	for name, trie := range tries {
+		t.Run(name, func(t *testing.T) {
+			got, err := ReadBinary(bytes.NewReader(encodeTrie(t, trie)))
+			if err != nil {
+				t.Fatalf("ReadBinary() = %v", err)
+			}
+			if err := got.Validate(); err != nil {
+				t.Fatalf("Validate() after ReadBinary = %v", err)
+			}
+			if !got.Equal(trie) {
+				t.Fatalf("round trip Words() = %q, want %q", got.Words(), trie.Words())
+			}
+			for _, word := range trie.Words() {
+				if got.Count(word) != trie.Count(word) {
+					t.Errorf("Count(%q) = %d after round trip, want %d", word, got.Count(word), trie.Count(word))
+				}
+			}
+		})
+	}
+}
+
+func TestReadBinaryRejectsBadInput(t *testing.T) {
+	data := encodeTrie(t, newTrieWith(sampleWords...))
+
+	// Every proper prefix of a valid stream is truncated somewhere.
+	for n := range len(data) {
+		if _, err := ReadBinary(bytes.NewReader(data[:n])); !errors.Is(err, ErrInvalidBinary) {
+			t.Fatalf("ReadBinary(first %d of %d bytes) = %v, want ErrInvalidBinary", n, len(data), err)
+		}
+	}
+
+	corrupt := func(i int, b byte) []byte {
+		c := bytes.Clone(data)
+		c[i] = b
+		return c
+	}
+	for name, input := range map[string][]byte{
+		"bad magic":      corrupt(0, 'X'),
+		"future version": corrupt(len(binaryMagic), binaryVersion+1),
+		"trailing bytes": append(bytes.Clone(data), 0),
+		"bad character":  corrupt(len(binaryMagic)+3, '!'), // First child's char
+	} {
+		if _, err := ReadBinary(bytes.NewReader(input)); !errors.Is(err, ErrInvalidBinary) {
+			t.Errorf("%s: ReadBinary() = %v, want ErrInvalidBinary", name, err)
+		}
+	}
+}
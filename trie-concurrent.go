@@ -0,0 +1,67 @@
+package main
+
+import "sync"
+
+// ConcurrentTrie is a goroutine-safe Trie guarded by a single RWMutex: any
+// number of readers run in parallel, while Insert and Delete take the lock
+// exclusively. It is the simplest choice; ShardedTrie reduces writer
+// contention and VersionedTrie lets readers skip locking altogether.
+type ConcurrentTrie struct {
+	mu   sync.RWMutex
+	trie *Trie
+}
+
+// NewConcurrentTrie creates a ConcurrentTrie around a NewTrie(opts...).
+func NewConcurrentTrie(opts ...Option) *ConcurrentTrie {
+	return &ConcurrentTrie{trie: NewTrie(opts...)}
+}
+
+// Insert adds a word to the Trie.
+// Assumes input 'word' contains only lowercase English letters.
+func (ct *ConcurrentTrie) Insert(word string) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.trie.Insert(word)
+}
+
+// Delete removes a word, reporting whether it was present.
+// Assumes input 'word' contains only lowercase English letters.
+func (ct *ConcurrentTrie) Delete(word string) bool {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.trie.Delete(word)
+}
+
+// Search checks if a word exists in the Trie.
+// Assumes input 'word' contains only lowercase English letters.
+func (ct *ConcurrentTrie) Search(word string) bool {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.trie.Search(word)
+}
+
+// StartsWith checks if there is any word in the Trie that starts with the given prefix.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (ct *ConcurrentTrie) StartsWith(prefix string) bool {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.trie.StartsWith(prefix)
+}
+
+// CollectAllWordsStartingWith collects all words that start with the given
+// prefix, in sorted order. The slice is fully built before the lock is
+// released and is never touched again, so callers own it outright.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (ct *ConcurrentTrie) CollectAllWordsStartingWith(prefix string) []string {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.trie.CollectAllWordsStartingWith(prefix)
+}
+
+// Suggest returns up to limit completions of prefix, most frequent first (see Trie.Suggest).
+// Assumes input 'prefix' contains only lowercase English letters.
+func (ct *ConcurrentTrie) Suggest(prefix string, limit int) []string {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.trie.Suggest(prefix, limit)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentTrieHammer runs a writer inserting new words and one deleting
+// old ones while readers search, suggest and collect; run it with -race to
+// check the locking. Readers also scribble over the slices they get back,
+// which must not affect anyone else.
+func TestConcurrentTrieHammer(t *testing.T) {
+	words := loadWords(t, "words.txt")
+	old, fresh := words[:len(words)/2], words[len(words)/2:]
+	ct := NewConcurrentTrie()
+	for _, word := range old {
+		ct.Insert(word)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for _, word := range fresh {
+			ct.Insert(word)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for _, word := range old {
+			ct.Delete(word)
+		}
+	}()
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := g; i < len(words); i += 8 {
+				word := words[i]
+				ct.Search(word)
+				ct.StartsWith(word[:1])
+				ct.Suggest(word[:1], 5)
+				got := ct.CollectAllWordsStartingWith(word[:1])
+				if !slices.IsSorted(got) {
+					t.Errorf("CollectAllWordsStartingWith(%q) is not sorted: %q", word[:1], got)
+					return
+				}
+				for j := range got {
+					got[j] = ""
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	want := newTrieWith(fresh...) // The halves share no words, so the writers never conflict
+	if got := ct.CollectAllWordsStartingWith(""); !slices.Equal(got, want.Words()) {
+		t.Errorf("CollectAllWordsStartingWith(\"\") has %d words, want %d", len(got), want.CountWords())
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// decayScore is a word's weight as of the moment it was last touched.
+type decayScore struct {
+	weight float64
+	at     time.Time
+}
+
+// decayed returns the weight remaining at now, halving every halfLife.
+func (s decayScore) decayed(now time.Time, halfLife time.Duration) float64 {
+	elapsed := now.Sub(s.at)
+	if elapsed <= 0 {
+		return s.weight
+	}
+	return s.weight * math.Exp2(-float64(elapsed)/float64(halfLife))
+}
+
+// DecayTrie ranks completions by an exponentially time-decayed frequency, so
+// a word searched often last week can lose to one searched a few times today.
+// Each Insert adds 1 to the word's weight, and a weight halves every halfLife.
+//
+// Decay is lazy: a word only stores its weight and when that was last
+// updated, and the decay is applied when the word is next inserted or
+// queried, so there is no background sweep.
+type DecayTrie struct {
+	words    *ValueTrie[decayScore]
+	halfLife time.Duration
+	now      func() time.Time
+}
+
+// DecayOption configures a DecayTrie in NewDecayTrie.
+type DecayOption func(*DecayTrie)
+
+// WithClock makes the DecayTrie read the current time from now instead of
+// time.Now, e.g. to drive it from a fake clock.
+func WithClock(now func() time.Time) DecayOption {
+	return func(t *DecayTrie) {
+		t.now = now
+	}
+}
+
+// NewDecayTrie creates a DecayTrie whose weights halve every halfLife.
+// It panics if halfLife is not positive.
+func NewDecayTrie(halfLife time.Duration, opts ...DecayOption) *DecayTrie {
+	if halfLife <= 0 {
+		panic("trie: DecayTrie half-life must be positive")
+	}
+	t := &DecayTrie{
+		words:    NewValueTrie[decayScore](),
+		halfLife: halfLife,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Insert records one occurrence of word at the current time.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *DecayTrie) Insert(word string) {
+	now := t.now()
+	bump := func(old decayScore) decayScore {
+		return decayScore{weight: old.decayed(now, t.halfLife) + 1, at: now}
+	}
+	if !t.words.Update(word, bump) {
+		t.words.Insert(word, decayScore{weight: 1, at: now})
+	}
+}
+
+// Score returns word's decayed weight at the current time, or 0 if it was never inserted.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *DecayTrie) Score(word string) float64 {
+	s, ok := t.words.Get(word)
+	if !ok {
+		return 0
+	}
+	return s.decayed(t.now(), t.halfLife)
+}
+
+// TopCompletions returns up to k words starting with prefix, highest decayed
+// weight first; equal weights are ordered alphabetically.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *DecayTrie) TopCompletions(prefix string, k int) []string {
+	node := t.words.find(prefix)
+	if node == nil || k <= 0 {
+		return []string{}
+	}
+
+	type scored struct {
+		word  string
+		score float64
+	}
+	now := t.now()
+	var matches []scored
+	t.words.walk(node, prefix, func(word string, s decayScore) {
+		matches = append(matches, scored{word, s.decayed(now, t.halfLife)})
+	})
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].word < matches[j].word
+	})
+
+	words := make([]string, 0, min(k, len(matches)))
+	for i := 0; i < len(matches) && i < k; i++ {
+		words = append(words, matches[i].word)
+	}
+	return words
+}
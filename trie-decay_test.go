@@ -0,0 +1,57 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable time source for WithClock.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestDecayTrieRecentBeatsOldButFrequent(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)}
+	trie := NewDecayTrie(24*time.Hour, WithClock(clock.Now))
+
+	for range 20 {
+		trie.Insert("golang") // Popular a week ago
+	}
+	clock.Advance(7 * 24 * time.Hour)
+	for range 3 {
+		trie.Insert("gopher") // Trending today
+	}
+	trie.Insert("goat")
+
+	if got, want := trie.TopCompletions("go", 3), []string{"gopher", "goat", "golang"}; !slices.Equal(got, want) {
+		t.Errorf(`TopCompletions("go", 3) = %q, want %q`, got, want)
+	}
+	// 20 halved seven times.
+	if got, want := trie.Score("golang"), 20.0/128; math.Abs(got-want) > 1e-9 {
+		t.Errorf(`Score("golang") = %v, want %v`, got, want)
+	}
+
+	// A burst of old inserts still wins right after it happens.
+	clock.Advance(time.Hour)
+	for range 10 {
+		trie.Insert("golang")
+	}
+	if got := trie.TopCompletions("go", 1); !slices.Equal(got, []string{"golang"}) {
+		t.Errorf(`TopCompletions("go", 1) after a new burst = %q, want [golang]`, got)
+	}
+
+	// Decay scales every weight by the same factor, so the ranking survives time passing.
+	clock.Advance(30 * 24 * time.Hour)
+	if got := trie.TopCompletions("go", 3); !slices.Equal(got, []string{"golang", "gopher", "goat"}) {
+		t.Errorf(`TopCompletions("go", 3) a month later = %q`, got)
+	}
+	if got := trie.TopCompletions("x", 3); got == nil || len(got) != 0 {
+		t.Errorf(`TopCompletions("x", 3) = %q, want []`, got)
+	}
+	if trie.Score("gone") != 0 {
+		t.Error(`Score("gone") for a word never inserted != 0`)
+	}
+}
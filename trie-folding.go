@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// letterFolds maps the lowercase letters that have no canonical decomposition,
+// so NFD leaves them whole, to the plain spelling they should match.
+var letterFolds = map[rune]string{
+	'æ': "ae",
+	'œ': "oe",
+	'ß': "ss",
+	'ø': "o",
+	'đ': "d",
+	'ħ': "h",
+	'ı': "i",
+	'ł': "l",
+	'ŧ': "t",
+}
+
+// foldDiacritics lowercases s, decomposes it with NFD and drops the combining
+// marks, so "Café", "café", "cafe\u0301" and "cafe" all fold to "cafe" and
+// "Ελλάδα" to "ελλαδα". Letters in letterFolds are spelled out; every other
+// rune is kept as is.
+func foldDiacritics(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue // Accent split off by NFD, or already decomposed in s
+		}
+		if plain, ok := letterFolds[r]; ok {
+			b.WriteString(plain)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// FoldingTrie is a RuneTrie that treats accented and unaccented spellings as
+// the same word. Every input is passed through foldDiacritics before it
+// reaches the underlying RuneTrie, and the original spelling of each word is
+// kept for display. Since RuneTrie takes any rune, whatever survives folding
+// (spaces, digits, other scripts) is stored and matched as is. Invalid UTF-8
+// is ignored by Insert and never found, as in RuneTrie.
+type FoldingTrie struct {
+	trie      *RuneTrie
+	originals map[string]string // folded key -> spelling it was first inserted with
+}
+
+// NewFoldingTrie creates and returns a new FoldingTrie.
+func NewFoldingTrie() *FoldingTrie {
+	return &FoldingTrie{
+		trie:      NewRuneTrie(),
+		originals: make(map[string]string),
+	}
+}
+
+// Insert adds word under its folded key. The first spelling inserted for a key
+// is the one Original reports.
+func (f *FoldingTrie) Insert(word string) {
+	if !utf8.ValidString(word) {
+		return // Folding would turn the bad bytes into U+FFFD and store a different word
+	}
+	key := foldDiacritics(word)
+	f.trie.Insert(key)
+	if _, ok := f.originals[key]; !ok {
+		f.originals[key] = word
+	}
+}
+
+// Search checks if word, after folding, is stored.
+func (f *FoldingTrie) Search(word string) bool {
+	return utf8.ValidString(word) && f.trie.Search(foldDiacritics(word))
+}
+
+// StartsWith checks if any stored word starts with prefix, after folding.
+func (f *FoldingTrie) StartsWith(prefix string) bool {
+	return utf8.ValidString(prefix) && f.trie.StartsWith(foldDiacritics(prefix))
+}
+
+// Original returns the display spelling stored for word's folded key.
+func (f *FoldingTrie) Original(word string) (string, bool) {
+	if !f.Search(word) {
+		return "", false
+	}
+	key := foldDiacritics(word)
+	original, ok := f.originals[key]
+	return original, ok
+}
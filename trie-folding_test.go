@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+func TestFoldingTrieMatchesAccentVariants(t *testing.T) {
+	tests := []struct {
+		name   string
+		insert string
+		query  string
+	}{
+		{"precomposed vs plain", "caf\u00e9", "cafe"},
+		{"plain vs precomposed", "cafe", "caf\u00e9"},
+		{"decomposed vs precomposed", "cafe\u0301", "caf\u00e9"},
+		{"precomposed vs decomposed", "\u00f1and\u00fa", "n\u0303andu\u0301"},
+		{"decomposed vs plain", "vie\u0323\u0302t", "viet"},
+		{"stacked marks precomposed", "Vi\u1ec7t Nam", "viet nam"},
+		{"latin extended additional", "\u1e43\u0101\u1e47\u1e0d\u016b", "mandu"},
+		{"uppercase accented", "CAFÉ", "cafe"},
+		{"space survives folding", "crème brûlée", "creme brulee"},
+		{"pinyin caron", "pǎo", "pao"},
+		{"greek tonos", "Ελλάδα", "ελλαδα"},
+		{"ligature", "œuvre", "oeuvre"},
+		{"sharp s", "straße", "strasse"},
+		{"stroke without decomposition", "Øresund", "oresund"},
+		{"unfolded script kept as is", "日本", "日本"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFoldingTrie()
+			f.Insert(tt.insert)
+			if !f.Search(tt.query) {
+				t.Errorf("after Insert(%q), Search(%q) = false, want true", tt.insert, tt.query)
+			}
+			if !f.Search(tt.insert) {
+				t.Errorf("Search(%q) of the inserted spelling = false, want true", tt.insert)
+			}
+			if original, ok := f.Original(tt.query); !ok || original != tt.insert {
+				t.Errorf("Original(%q) = %q, %t, want %q, true", tt.query, original, ok, tt.insert)
+			}
+		})
+	}
+}
+
+func TestFoldingTrieNeverPanics(t *testing.T) {
+	f := NewFoldingTrie()
+	f.Insert("naïve")
+	for _, q := range []string{"Ελλάδα", "crème brûlée", "pǎo", "1234", "a\x00b", "\xff\xfe", ""} {
+		if f.Search(q) {
+			t.Errorf("Search(%q) = true, want false", q)
+		}
+		f.StartsWith(q)
+		f.Original(q)
+	}
+	if !f.StartsWith("NAI") {
+		t.Error(`StartsWith("NAI") = false, want true for "naïve"`)
+	}
+
+	f.Insert("\xffbad")
+	if f.Search("\xffbad") || f.Search("�bad") {
+		t.Error("invalid UTF-8 was stored")
+	}
+}
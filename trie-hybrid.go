@@ -0,0 +1,173 @@
+package main
+
+import "unsafe"
+
+// sparseMaxChildren is the number of children a hybrid node keeps in its
+// sparse list before it is promoted to a dense array. A sparse edge costs
+// 16 bytes against 8 per dense slot, so up to here sparse is still smaller.
+const sparseMaxChildren = 8
+
+// hybridEdge is one entry of a sparse child list.
+type hybridEdge struct {
+	char byte
+	node *hybridNode
+}
+
+// hybridNode stores its children either as a short list sorted by char
+// (sparse, typical deep in the trie) or as a [alphabetSize] array (dense,
+// typical near the root). dense is nil until the node is promoted.
+type hybridNode struct {
+	sparse      []hybridEdge
+	dense       *[alphabetSize]*hybridNode
+	isEndOfWord bool
+}
+
+// child returns the child along char, or nil.
+func (n *hybridNode) child(char byte) *hybridNode {
+	if n.dense != nil {
+		return n.dense[charToIndex(char)]
+	}
+	for _, e := range n.sparse {
+		if e.char == char {
+			return e.node
+		}
+	}
+	return nil
+}
+
+// addChild creates and returns a child along char, which must not exist yet,
+// promoting the node to the dense layout when the sparse list would overflow.
+func (n *hybridNode) addChild(char byte) *hybridNode {
+	c := &hybridNode{}
+	if n.dense == nil && len(n.sparse) >= sparseMaxChildren {
+		n.dense = &[alphabetSize]*hybridNode{}
+		for _, e := range n.sparse {
+			n.dense[charToIndex(e.char)] = e.node
+		}
+		n.sparse = nil
+	}
+	if n.dense != nil {
+		n.dense[charToIndex(char)] = c
+		return c
+	}
+
+	// Keep the sparse list sorted by index so traversal order matches Trie.
+	idx := charToIndex(char)
+	pos := 0
+	for pos < len(n.sparse) && charToIndex(n.sparse[pos].char) < idx {
+		pos++
+	}
+	n.sparse = append(n.sparse, hybridEdge{})
+	copy(n.sparse[pos+1:], n.sparse[pos:])
+	n.sparse[pos] = hybridEdge{char: char, node: c}
+	return c
+}
+
+// eachChild calls fn for every child in index order.
+func (n *hybridNode) eachChild(fn func(char byte, child *hybridNode)) {
+	if n.dense != nil {
+		for i, c := range n.dense {
+			if c != nil {
+				fn(indexToChar(i), c)
+			}
+		}
+		return
+	}
+	for _, e := range n.sparse {
+		fn(e.char, e.node)
+	}
+}
+
+// HybridTrie is a Trie whose nodes start with a compact sparse child list and
+// switch to the fixed-size array once they have many children, trading a short
+// linear scan on sparse nodes for a much smaller footprint on deep levels.
+// It accepts the same alphabet as Trie.
+type HybridTrie struct {
+	root *hybridNode
+}
+
+// NewHybridTrie creates and returns a new HybridTrie.
+func NewHybridTrie() *HybridTrie {
+	return &HybridTrie{root: &hybridNode{}}
+}
+
+// Insert adds a word to the HybridTrie.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *HybridTrie) Insert(word string) {
+	currentNode := t.root
+	for i := 0; i < len(word); i++ {
+		next := currentNode.child(word[i])
+		if next == nil {
+			next = currentNode.addChild(word[i])
+		}
+		currentNode = next
+	}
+	currentNode.isEndOfWord = true
+}
+
+// Search checks if a word exists in the HybridTrie.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *HybridTrie) Search(word string) bool {
+	node := t.find(word)
+	return node != nil && node.isEndOfWord
+}
+
+// StartsWith checks if there is any word in the HybridTrie that starts with the given prefix.
+// As with Trie.StartsWith, on an empty HybridTrie even the empty prefix reports false.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *HybridTrie) StartsWith(prefix string) bool {
+	node := t.find(prefix)
+	// Nothing is ever deleted, so only the root of an empty trie has no word below it.
+	return node != nil && (node.isEndOfWord || len(node.sparse) > 0 || node.dense != nil)
+}
+
+// CollectAllWordsStartingWith collects all words that start with the given
+// prefix, in the same sorted order as Trie.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *HybridTrie) CollectAllWordsStartingWith(prefix string) []string {
+	words := []string{}
+	node := t.find(prefix)
+	if node == nil {
+		return words
+	}
+	var dfs func(node *hybridNode, currentWord string)
+	dfs = func(node *hybridNode, currentWord string) {
+		if node.isEndOfWord {
+			words = append(words, currentWord)
+		}
+		node.eachChild(func(char byte, child *hybridNode) {
+			dfs(child, currentWord+string(char))
+		})
+	}
+	dfs(node, prefix)
+	return words
+}
+
+// find returns the node reached by following s from the root, or nil.
+func (t *HybridTrie) find(s string) *hybridNode {
+	currentNode := t.root
+	for i := 0; i < len(s); i++ {
+		currentNode = currentNode.child(s[i])
+		if currentNode == nil {
+			return nil
+		}
+	}
+	return currentNode
+}
+
+// EstimatedBytes returns an approximate heap footprint, comparable to
+// Trie.EstimatedBytes: node headers plus sparse list capacity plus dense arrays.
+func (t *HybridTrie) EstimatedBytes() int {
+	total := int(unsafe.Sizeof(HybridTrie{}))
+	var dfs func(node *hybridNode)
+	dfs = func(node *hybridNode) {
+		total += int(unsafe.Sizeof(hybridNode{}))
+		total += cap(node.sparse) * int(unsafe.Sizeof(hybridEdge{}))
+		if node.dense != nil {
+			total += int(unsafe.Sizeof(*node.dense))
+		}
+		node.eachChild(func(_ byte, child *hybridNode) { dfs(child) })
+	}
+	dfs(t.root)
+	return total
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHybridTrieMatchesTrie(t *testing.T) {
+	rng := newTestRand(146)
+	// A small alphabet makes shallow nodes dense and deep ones sparse.
+	words := randomWords(rng, 2000, 0, 8, 12)
+	trie, hybrid := NewTrie(), NewHybridTrie()
+	for _, word := range words {
+		trie.Insert(word)
+		hybrid.Insert(word)
+	}
+
+	dense, sparse := 0, 0
+	var count func(node *hybridNode)
+	count = func(node *hybridNode) {
+		if node.dense != nil {
+			dense++
+		} else if len(node.sparse) > 0 {
+			sparse++
+		}
+		node.eachChild(func(_ byte, child *hybridNode) { count(child) })
+	}
+	count(hybrid.root)
+	if dense == 0 || sparse == 0 {
+		t.Fatalf("got %d dense and %d sparse nodes, want both layouts exercised", dense, sparse)
+	}
+
+	for _, probe := range append(randomWords(rng, 2000, 0, 9, 13), words...) {
+		if got, want := hybrid.Search(probe), trie.Search(probe); got != want {
+			t.Fatalf("Search(%q) = %t, want %t", probe, got, want)
+		}
+		if got, want := hybrid.StartsWith(probe), trie.StartsWith(probe); got != want {
+			t.Fatalf("StartsWith(%q) = %t, want %t", probe, got, want)
+		}
+	}
+	for _, prefix := range []string{"", "a", "ab", "lk", "m"} {
+		if got, want := hybrid.CollectAllWordsStartingWith(prefix), trie.CollectAllWordsStartingWith(prefix); !slices.Equal(got, want) {
+			t.Errorf("CollectAllWordsStartingWith(%q) has %d words, want %d", prefix, len(got), len(want))
+		}
+	}
+}
+
+func TestHybridTrieUsesLessMemory(t *testing.T) {
+	trie, hybrid := NewTrie(), NewHybridTrie()
+	for _, word := range sortedDictionary(t) {
+		trie.Insert(word)
+		hybrid.Insert(word)
+	}
+	if h, a := hybrid.EstimatedBytes(), trie.EstimatedBytes(); h >= a/2 {
+		t.Errorf("HybridTrie.EstimatedBytes() = %d, want well under half of Trie's %d", h, a)
+	}
+}
+
+// nodeLayoutTrie is the subset of methods BenchmarkNodeLayouts exercises.
+type nodeLayoutTrie interface {
+	Insert(word string)
+	Search(word string) bool
+	EstimatedBytes() int
+}
+
+// BenchmarkNodeLayouts builds and searches the same dictionary with the
+// array-per-node Trie and the sparse/dense HybridTrie, reporting each one's
+// estimated footprint as a bytes/trie metric.
+func BenchmarkNodeLayouts(b *testing.B) {
+	words := sortedDictionary(b)
+	layouts := []struct {
+		name string
+		new  func() nodeLayoutTrie
+	}{
+		{"Array", func() nodeLayoutTrie { return NewTrie() }},
+		{"Hybrid", func() nodeLayoutTrie { return NewHybridTrie() }},
+	}
+	for _, layout := range layouts {
+		b.Run(layout.name+"/Build", func(b *testing.B) {
+			b.ReportAllocs()
+			var trie nodeLayoutTrie
+			for i := 0; i < b.N; i++ {
+				trie = layout.new()
+				for _, word := range words {
+					trie.Insert(word)
+				}
+			}
+			b.ReportMetric(float64(trie.EstimatedBytes()), "bytes/trie")
+		})
+		b.Run(layout.name+"/Search", func(b *testing.B) {
+			trie := layout.new()
+			for _, word := range words {
+				trie.Insert(word)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				trie.Search(words[i%len(words)])
+			}
+		})
+	}
+}
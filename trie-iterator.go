@@ -0,0 +1,86 @@
+package main
+
+// iteratorFrame is one level of a WordIterator's DFS stack.
+type iteratorFrame struct {
+	node *Node
+	next int // Next child index to visit; -1 while node itself is still unvisited
+}
+
+// WordIterator walks a Trie's words in sorted order with an explicit DFS stack,
+// so it can be paused, resumed and repositioned with Seek. Each step costs at
+// most a scan over a node's child array per level. The Trie must not be
+// modified while an iterator is in use.
+type WordIterator struct {
+	root  *Node
+	stack []iteratorFrame
+	path  []byte // Characters leading to the top frame's node
+}
+
+// Iterator returns a WordIterator positioned before the first word.
+func (t *Trie) Iterator() *WordIterator {
+	it := &WordIterator{root: t.root}
+	it.Seek("")
+	return it
+}
+
+// Seek positions the iterator so that the next call to Next returns the first
+// stored word that is >= word in byte order. word may contain any bytes,
+// which makes resuming from the last word of a previous page straightforward.
+func (it *WordIterator) Seek(word string) {
+	it.stack = append(it.stack[:0], iteratorFrame{node: it.root, next: -1})
+	it.path = it.path[:0]
+
+	for i := 0; i < len(word); i++ {
+		top := &it.stack[len(it.stack)-1]
+		idx, ok := tryCharToIndex(word[i])
+		if !ok {
+			// Not in the alphabet: resume from the first child that sorts after it.
+			top.next = 0
+			for top.next < alphabetSize && indexToChar(top.next) < word[i] {
+				top.next++
+			}
+			return
+		}
+
+		// The node itself is a proper prefix of word, so it sorts before it;
+		// after the matching child, carry on with its larger siblings.
+		top.next = idx + 1
+		child := top.node.children[idx]
+		if child == nil {
+			return
+		}
+		it.stack = append(it.stack, iteratorFrame{node: child, next: -1})
+		it.path = append(it.path, word[i])
+	}
+}
+
+// Next returns the next word in sorted order, or ok=false once all words have been returned.
+func (it *WordIterator) Next() (string, bool) {
+	for len(it.stack) > 0 {
+		top := &it.stack[len(it.stack)-1]
+		if top.next == -1 {
+			top.next = 0
+			if top.node.isEndOfWord {
+				return string(it.path), true
+			}
+		}
+
+		for top.next < alphabetSize && top.node.children[top.next] == nil {
+			top.next++
+		}
+		if top.next < alphabetSize {
+			i := top.next
+			top.next++
+			it.stack = append(it.stack, iteratorFrame{node: top.node.children[i], next: -1})
+			it.path = append(it.path, indexToChar(i))
+			continue
+		}
+
+		// Subtree exhausted
+		it.stack = it.stack[:len(it.stack)-1]
+		if len(it.path) > 0 {
+			it.path = it.path[:len(it.path)-1]
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+// remaining drains it and returns every word it still yields.
+func remaining(it *WordIterator) []string {
+	words := []string{}
+	for word, ok := it.Next(); ok; word, ok = it.Next() {
+		words = append(words, word)
+	}
+	return words
+}
+
+func TestWordIteratorSeek(t *testing.T) {
+	rng := newTestRand(147)
+	trie := newTrieWith(randomWords(rng, 400, 0, 6, 4)...)
+	trie.Insert("don't")
+	trie.Insert("mother-in-law")
+	words := trie.Words()
+
+	it := trie.Iterator()
+	if got := remaining(it); !slices.Equal(got, words) {
+		t.Fatalf("full iteration has %d words, want %d", len(got), len(words))
+	}
+	if _, ok := it.Next(); ok {
+		t.Error("Next() after the end ok = true, want false")
+	}
+
+	seeks := []string{"", "a", "abc", "b", "bzz", "c'", "da", "don't", "don'u", "mother", "zzz", "A", "~", "a~", "b\x00", "c-a"}
+	seeks = append(seeks, words...)
+	seeks = append(seeks, randomWords(rng, 200, 0, 7, 6)...)
+	for _, seek := range seeks {
+		i := sort.SearchStrings(words, seek) // First word >= seek
+		it.Seek(seek)
+		if got := remaining(it); !slices.Equal(got, words[i:]) {
+			t.Errorf("Seek(%q) then Next yields %d words starting %q, want %d starting at index %d",
+				seek, len(got), got[:min(1, len(got))], len(words)-i, i)
+		}
+	}
+}
+
+// TestWordIteratorResume pages through the words with a fresh iterator per
+// page, seeking just past the last word seen, as a resuming client would.
+func TestWordIteratorResume(t *testing.T) {
+	const pageSize = 25
+	trie := newTrieWith(loadWords(t, "words.txt")...)
+	var got []string
+	for {
+		it := trie.Iterator()
+		if len(got) > 0 {
+			it.Seek(got[len(got)-1] + "\x00") // Smallest string after the last word
+		}
+		n := 0
+		for ; n < pageSize; n++ {
+			word, ok := it.Next()
+			if !ok {
+				break
+			}
+			got = append(got, word)
+		}
+		if n < pageSize {
+			break
+		}
+	}
+	if want := trie.Words(); !slices.Equal(got, want) {
+		t.Errorf("resumed pages hold %d words, want %d", len(got), len(want))
+	}
+}
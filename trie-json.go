@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The JSON form of a Trie is an object mapping each stored word to its
+// insertion count, e.g. {"car":1,"cat":2}. encoding/json writes the keys in
+// sorted order, so equal Tries always marshal to identical bytes. Options
+// such as the input policy are not part of the encoding.
+
+// MarshalJSON implements json.Marshaler.
+func (t *Trie) MarshalJSON() ([]byte, error) {
+	s := t.Checkpoint()
+	counts := make(map[string]int, len(s.words))
+	for i, word := range s.words {
+		counts[word] = s.counts[i]
+	}
+	return json.Marshal(counts)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It replaces the Trie's contents
+// with the decoded words and counts, keeping its options. It fails, leaving
+// the Trie unchanged, if a word has a character outside the alphabet or a
+// count below 1.
+func (t *Trie) UnmarshalJSON(data []byte) error {
+	var counts map[string]int
+	if err := json.Unmarshal(data, &counts); err != nil {
+		return err
+	}
+
+	var s Snapshot
+	for word, count := range counts {
+		for i := 0; i < len(word); i++ {
+			if _, ok := tryCharToIndex(word[i]); !ok {
+				return fmt.Errorf("trie: JSON word %q: %w", word, InvalidCharError{Char: word[i], Pos: i})
+			}
+		}
+		if count < 1 {
+			return fmt.Errorf("trie: JSON word %q has count %d, want at least 1", word, count)
+		}
+		s.words = append(s.words, word)
+		s.counts = append(s.counts, count)
+	}
+	t.Restore(s)
+	return nil
+}
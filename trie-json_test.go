@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	// About 1000 words: the word list topped up with random ones.
+	words := loadWords(t, "words.txt")
+	words = append(words, randomWords(newTestRand(263), 1000-len(words), 1, 10, 26)...)
+	tries := map[string]*Trie{
+		"empty":       NewTrie(),
+		"empty word":  newTrieWith("", "a"),
+		"word list":   newTrieWith(words...),
+		"soft delete": newTrieWith("app", "apple", "application", "app"),
+	}
+	tries["soft delete"].Delete("application")
+
+	for name, trie := range tries {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(trie)
+			if err != nil {
+				t.Fatalf("Marshal() = %v", err)
+			}
+			got := NewTrie()
+			if err := json.Unmarshal(data, got); err != nil {
+				t.Fatalf("Unmarshal() = %v", err)
+			}
+			checkTrieInvariant(t, got)
+			if !got.Equal(trie) {
+				t.Fatalf("round trip Words() = %q, want %q", got.Words(), trie.Words())
+			}
+			for _, word := range append(trie.Words(), "application", "zzzzzz") {
+				for i := range len(word) + 1 {
+					p := word[:i]
+					if got.Search(p) != trie.Search(p) || got.StartsWith(p) != trie.StartsWith(p) {
+						t.Fatalf("Search/StartsWith(%q) differ after round trip", p)
+					}
+				}
+				if got.Count(word) != trie.Count(word) {
+					t.Errorf("Count(%q) = %d after round trip, want %d", word, got.Count(word), trie.Count(word))
+				}
+			}
+
+			again, err := json.Marshal(got)
+			if err != nil || string(again) != string(data) {
+				t.Errorf("second Marshal() = %s, %v; want %s", again, err, data)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSONRejectsBadInput(t *testing.T) {
+	for name, input := range map[string]string{
+		"not an object": `["cat"]`,
+		"malformed":     `{"cat":1`,
+		"bad character": `{"cat":1,"Dog":1}`,
+		"zero count":    `{"cat":0}`,
+		"negative":      `{"cat":-2}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			trie := newTrieWith("keep")
+			if err := json.Unmarshal([]byte(input), trie); err == nil {
+				t.Fatalf("Unmarshal(%s) = nil, want an error", input)
+			}
+			if got := trie.Words(); len(got) != 1 || got[0] != "keep" {
+				t.Errorf("Words() after a failed Unmarshal = %q, want [keep]", got)
+			}
+		})
+	}
+
+	var charErr InvalidCharError
+	err := json.Unmarshal([]byte(`{"Dog":1}`), NewTrie())
+	if !errors.As(err, &charErr) || charErr.Char != 'D' || charErr.Pos != 0 {
+		t.Errorf("Unmarshal of an uppercase word = %v, want an InvalidCharError for 'D' at 0", err)
+	}
+}
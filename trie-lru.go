@@ -0,0 +1,74 @@
+package main
+
+import "container/list"
+
+// LRUTrie is a Trie that holds at most a fixed number of words. Once full,
+// inserting a new word evicts the least recently used one, where both Insert
+// and a successful Search count as a use. Evicted words are hard-deleted, so
+// the node count stays bounded along with the word count.
+type LRUTrie struct {
+	trie     *Trie
+	capacity int
+	recency  *list.List               // Front is most recently used; values are words
+	elements map[string]*list.Element // word -> its entry in recency
+}
+
+// NewLRUTrie creates an LRUTrie that keeps at most capacity words.
+// It panics if capacity is not positive.
+func NewLRUTrie(capacity int) *LRUTrie {
+	if capacity <= 0 {
+		panic("trie: LRUTrie capacity must be positive")
+	}
+	return &LRUTrie{
+		trie:     NewTrie(),
+		capacity: capacity,
+		recency:  list.New(),
+		elements: make(map[string]*list.Element),
+	}
+}
+
+// Insert adds word (or refreshes it if already present) as the most recently
+// used word. If this pushes the Trie past capacity, the least recently used
+// word is deleted and returned with evicted=true.
+// It panics with an InvalidCharError if word contains characters outside the
+// alphabet, before evicting anything.
+func (l *LRUTrie) Insert(word string) (evictedWord string, evicted bool) {
+	mustBeInAlphabet(word) // A failed insert must not cost a word
+	if e, ok := l.elements[word]; ok {
+		l.trie.Insert(word)
+		l.recency.MoveToFront(e)
+		return "", false
+	}
+
+	if l.recency.Len() >= l.capacity {
+		oldest := l.recency.Back()
+		evictedWord = l.recency.Remove(oldest).(string)
+		delete(l.elements, evictedWord)
+		l.trie.HardDelete(evictedWord)
+		evicted = true
+	}
+
+	l.trie.Insert(word)
+	l.elements[word] = l.recency.PushFront(word)
+	return evictedWord, evicted
+}
+
+// Search checks if word is stored, marking it as most recently used if so.
+func (l *LRUTrie) Search(word string) bool {
+	e, ok := l.elements[word]
+	if !ok {
+		return false
+	}
+	l.recency.MoveToFront(e)
+	return true
+}
+
+// StartsWith checks if any stored word starts with prefix. It does not affect recency.
+func (l *LRUTrie) StartsWith(prefix string) bool {
+	return l.trie.StartsWith(prefix)
+}
+
+// Len returns the number of words currently stored.
+func (l *LRUTrie) Len() int {
+	return l.recency.Len()
+}
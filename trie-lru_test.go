@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLRUTrieEvictsLeastRecentlyUsed(t *testing.T) {
+	l := NewLRUTrie(3)
+	for _, word := range []string{"a", "b", "c"} {
+		if _, evicted := l.Insert(word); evicted {
+			t.Fatalf("Insert(%q) evicted below capacity", word)
+		}
+	}
+	l.Search("a") // Order from oldest: b, c, a
+	l.Insert("c") // Refresh: b, a, c
+
+	steps := []struct {
+		insert  string
+		evicted string
+	}{
+		{"d", "b"},
+		{"e", "a"},
+		{"f", "c"},
+		{"g", "d"},
+	}
+	for _, s := range steps {
+		got, evicted := l.Insert(s.insert)
+		if !evicted || got != s.evicted {
+			t.Fatalf("Insert(%q) evicted %q, %t, want %q, true", s.insert, got, evicted, s.evicted)
+		}
+		if l.Search(s.evicted) || l.StartsWith(s.evicted) {
+			t.Errorf("evicted word %q is still found", s.evicted)
+		}
+		if l.Len() != 3 {
+			t.Errorf("Len() = %d, want 3", l.Len())
+		}
+	}
+	if got := l.trie.Words(); !slices.Equal(got, []string{"e", "f", "g"}) {
+		t.Errorf("stored words = %q, want [e f g]", got)
+	}
+	if err := l.trie.Validate(); err != nil {
+		t.Errorf("Validate() = %v; evictions should leave no dead nodes", err)
+	}
+}
+
+func TestLRUTrieSearchMissDoesNotTouchRecency(t *testing.T) {
+	l := NewLRUTrie(2)
+	l.Insert("a")
+	l.Insert("b")
+	l.Search("zzz")
+	if got, _ := l.Insert("c"); got != "a" {
+		t.Errorf("Insert(\"c\") evicted %q, want \"a\"", got)
+	}
+}
+
+func TestLRUTrieInvalidInsertEvictsNothing(t *testing.T) {
+	l := NewLRUTrie(1)
+	l.Insert("a")
+	func() {
+		defer func() {
+			if err, _ := recover().(error); !errors.Is(err, ErrInvalidChar) {
+				t.Errorf(`Insert("B") recovered %v, want an InvalidCharError`, err)
+			}
+		}()
+		l.Insert("B")
+	}()
+	if l.Len() != 1 || !l.Search("a") {
+		t.Errorf(`after a rejected insert Len() = %d, Search("a") = %t, want 1, true`, l.Len(), l.Search("a"))
+	}
+}
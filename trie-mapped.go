@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// MappedTrie answers queries directly against a buffer in the WriteBinary
+// format, without rebuilding the node graph. The buffer is typically a
+// read-only mmap of a file shared between processes; MappedTrie never writes
+// to it or copies it. Lookups skip unrelated siblings using the stored subtree
+// lengths, so they cost O(len(word) * alphabetSize).
+type MappedTrie struct {
+	data []byte // Encoded root node and everything below it
+}
+
+// NewMappedTrie wraps data, which must hold a Trie written by WriteBinary.
+// Only the header is checked up front; a corrupt body makes queries report
+// false rather than panic.
+func NewMappedTrie(data []byte) (*MappedTrie, error) {
+	if len(data) < len(binaryMagic)+1 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return nil, fmt.Errorf("%w: missing %q header", ErrInvalidBinary, binaryMagic)
+	}
+	if v := data[len(binaryMagic)]; v != binaryVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidBinary, v)
+	}
+	return &MappedTrie{data: data[len(binaryMagic)+1:]}, nil
+}
+
+// Search checks if a word exists in the MappedTrie.
+func (m *MappedTrie) Search(word string) bool {
+	node, ok := m.find(word)
+	if !ok || node >= len(m.data) {
+		return false
+	}
+	return m.data[node]&flagEndOfWord != 0
+}
+
+// StartsWith checks if there is any word in the MappedTrie that starts with the given prefix.
+func (m *MappedTrie) StartsWith(prefix string) bool {
+	node, ok := m.find(prefix)
+	return ok && m.hasWords(node)
+}
+
+// hasWords reports whether the node at offset node ends a word or has children.
+// WriteBinary drops branches that lead to no word, so only the root of an
+// empty Trie can fail this.
+func (m *MappedTrie) hasWords(node int) bool {
+	if node+1 >= len(m.data) {
+		return false
+	}
+	return m.data[node]&flagEndOfWord != 0 || m.data[node+1] > 0
+}
+
+// find returns the offset of the node reached by following s from the root.
+func (m *MappedTrie) find(s string) (int, bool) {
+	node := 0
+	for i := 0; i < len(s); i++ {
+		child, ok := m.child(node, s[i])
+		if !ok {
+			return 0, false
+		}
+		node = child
+	}
+	return node, true
+}
+
+// child returns the offset of node's child along char, scanning the child list
+// and skipping over the subtrees of the other children.
+func (m *MappedTrie) child(node int, char byte) (int, bool) {
+	data := m.data
+	pos := node
+	if pos >= len(data) {
+		return 0, false
+	}
+	flags := data[pos]
+	pos++
+	if flags&flagEndOfWord != 0 {
+		_, n := binary.Uvarint(data[pos:])
+		if n <= 0 {
+			return 0, false
+		}
+		pos += n
+	}
+
+	if pos >= len(data) {
+		return 0, false
+	}
+	childCount := int(data[pos])
+	pos++
+
+	for c := 0; c < childCount; c++ {
+		if pos >= len(data) {
+			return 0, false
+		}
+		childChar := data[pos]
+		pos++
+		size, n := binary.Uvarint(data[pos:])
+		if n <= 0 || size > uint64(len(data)-pos-n) {
+			return 0, false
+		}
+		pos += n
+		if childChar == char {
+			return pos, true
+		}
+		pos += int(size) // Skip this child's subtree
+	}
+	return 0, false
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestMappedTrieMatchesTrie checks Search and StartsWith on a MappedTrie
+// against the Trie it was written from, for stored words, their prefixes and
+// random probes.
+func TestMappedTrieMatchesTrie(t *testing.T) {
+	rng := newTestRand(137)
+	deleted := newTrieWith("app", "apple", "application", "")
+	deleted.Delete("application")
+	deleted.Delete("")
+
+	for name, trie := range map[string]*Trie{
+		"empty":       NewTrie(),
+		"empty word":  newTrieWith(""),
+		"soft delete": deleted,
+		"word list":   newTrieWith(loadWords(t, "words.txt")...),
+		"random":      newTrieWith(randomWords(rng, 300, 1, 7, 4)...),
+	} {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewMappedTrie(encodeTrie(t, trie))
+			if err != nil {
+				t.Fatalf("NewMappedTrie() = %v", err)
+			}
+			probes := []string{"", "applic", "application", "zzz"}
+			for _, word := range trie.Words() {
+				for i := range len(word) + 2 {
+					probes = append(probes, word[:min(i, len(word))], word+"s")
+				}
+			}
+			probes = append(probes, randomWords(rng, 200, 0, 7, 5)...)
+
+			for _, s := range probes {
+				if got, want := m.Search(s), trie.Search(s); got != want {
+					t.Errorf("Search(%q) = %t, want %t", s, got, want)
+				}
+				if got, want := m.StartsWith(s), trie.StartsWith(s); got != want {
+					t.Errorf("StartsWith(%q) = %t, want %t", s, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMappedTrieBadInput(t *testing.T) {
+	data := encodeTrie(t, newTrieWith(sampleWords...))
+	if _, err := NewMappedTrie(data[:3]); !errors.Is(err, ErrInvalidBinary) {
+		t.Errorf("NewMappedTrie(short header) = %v, want ErrInvalidBinary", err)
+	}
+
+	// A truncated body is only noticed lazily: queries say false, never panic.
+	for n := len(binaryMagic) + 1; n < len(data); n++ {
+		m, err := NewMappedTrie(data[:n])
+		if err != nil {
+			t.Fatalf("NewMappedTrie(first %d bytes) = %v", n, err)
+		}
+		for _, word := range sampleWords {
+			m.Search(word)
+			m.StartsWith(word)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import "sort"
+
+// persistentNode is an immutable Trie node: once reachable from a
+// PersistentTrie it is never modified, only copied.
+type persistentNode struct {
+	children    [alphabetSize]*persistentNode
+	isEndOfWord bool
+	count       int // How many times the word ending here was inserted
+}
+
+// PersistentTrie is an immutable Trie. Insert and Delete return a new version
+// that copies only the nodes on the modified path and shares every other
+// subtree with the version it came from, so old versions stay valid and cheap
+// to keep around (undo stacks, MVCC-style readers).
+type PersistentTrie struct {
+	root *persistentNode // nil for the empty Trie
+	size int             // Number of stored words
+}
+
+// NewPersistentTrie returns an empty PersistentTrie.
+func NewPersistentTrie() *PersistentTrie {
+	return &PersistentTrie{}
+}
+
+// Len returns the number of words in this version.
+func (t *PersistentTrie) Len() int {
+	return t.size
+}
+
+// Insert returns a version that also contains word. Inserting a word that is
+// already present still returns a new version, with its Count one higher.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *PersistentTrie) Insert(word string) *PersistentTrie {
+	size := t.size
+	if !t.Search(word) {
+		size++
+	}
+	return &PersistentTrie{
+		root: insertPersistent(t.root, word, 0),
+		size: size,
+	}
+}
+
+// insertPersistent returns a copy of node (or a new node if nil) with word[depth:] added below it.
+func insertPersistent(node *persistentNode, word string, depth int) *persistentNode {
+	clone := &persistentNode{}
+	if node != nil {
+		*clone = *node // Shares all children; only the path gets replaced below
+	}
+	if depth == len(word) {
+		clone.isEndOfWord = true
+		clone.count++
+		return clone
+	}
+	idx := charToIndex(word[depth])
+	clone.children[idx] = insertPersistent(clone.children[idx], word, depth+1)
+	return clone
+}
+
+// Delete returns a version without word, pruning nodes that no longer lead to
+// a word, and whether word was present. If it wasn't, t itself is returned.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *PersistentTrie) Delete(word string) (*PersistentTrie, bool) {
+	if !t.Search(word) {
+		return t, false
+	}
+	return &PersistentTrie{
+		root: deletePersistent(t.root, word, 0),
+		size: t.size - 1,
+	}, true
+}
+
+// deletePersistent returns a copy of node with word[depth:] removed below it,
+// or nil if the copy would neither end a word nor have children.
+func deletePersistent(node *persistentNode, word string, depth int) *persistentNode {
+	clone := *node
+	if depth == len(word) {
+		clone.isEndOfWord = false
+		clone.count = 0
+	} else {
+		idx := charToIndex(word[depth])
+		clone.children[idx] = deletePersistent(node.children[idx], word, depth+1)
+	}
+
+	if clone.isEndOfWord {
+		return &clone
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if clone.children[i] != nil {
+			return &clone
+		}
+	}
+	return nil
+}
+
+// Search checks if a word exists in this version.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *PersistentTrie) Search(word string) bool {
+	node := t.find(word)
+	return node != nil && node.isEndOfWord
+}
+
+// Count returns how many times word was inserted on the way to this version
+// since it was last deleted, or 0 if it is not stored.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *PersistentTrie) Count(word string) int {
+	if node := t.find(word); node != nil {
+		return node.count
+	}
+	return 0
+}
+
+// StartsWith checks if any word in this version starts with the given prefix.
+// Delete prunes every node that no longer leads to a word, and an empty
+// version has no root, so like Trie.StartsWith it never reports a dead path
+// and reports false for the empty prefix on an empty version.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *PersistentTrie) StartsWith(prefix string) bool {
+	return t.find(prefix) != nil
+}
+
+// CollectAllWordsStartingWith collects all words in this version that start
+// with the given prefix, in sorted order.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *PersistentTrie) CollectAllWordsStartingWith(prefix string) []string {
+	words := []string{}
+	if node := t.find(prefix); node != nil {
+		collectPersistent(node, prefix, &words)
+	}
+	return words
+}
+
+// CollectWithLimit is CollectAllWordsStartingWith capped at the first limit
+// words in sorted order; the DFS stops as soon as the cap is reached.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *PersistentTrie) CollectWithLimit(prefix string, limit int) []string {
+	words := []string{}
+	if node := t.find(prefix); node != nil && limit > 0 {
+		collectPersistentLimit(node, []byte(prefix), limit, &words)
+	}
+	return words
+}
+
+// Suggest returns up to limit words starting with prefix, most frequently
+// inserted first (see Count); equally frequent words are ordered
+// alphabetically, as in Trie.Suggest. A limit of 0 or less returns an empty
+// slice. Every word below prefix is ranked, so it costs O(m log m) for m
+// completions.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *PersistentTrie) Suggest(prefix string, limit int) []string {
+	node := t.find(prefix)
+	if node == nil || limit <= 0 {
+		return []string{}
+	}
+
+	var words []string
+	var counts []int
+	var walk func(node *persistentNode, path []byte)
+	walk = func(node *persistentNode, path []byte) {
+		if node.isEndOfWord {
+			words = append(words, string(path))
+			counts = append(counts, node.count)
+		}
+		for i := 0; i < alphabetSize; i++ {
+			if child := node.children[i]; child != nil {
+				walk(child, append(path, indexToChar(i)))
+			}
+		}
+	}
+	walk(node, []byte(prefix))
+
+	order := make([]int, len(words))
+	for i := range order {
+		order[i] = i
+	}
+	// The DFS already yields words sorted, so a stable sort by count keeps ties alphabetical.
+	sort.SliceStable(order, func(i, j int) bool {
+		return counts[order[i]] > counts[order[j]]
+	})
+
+	result := make([]string, 0, min(limit, len(order)))
+	for _, i := range order[:min(limit, len(order))] {
+		result = append(result, words[i])
+	}
+	return result
+}
+
+// collectPersistentLimit is a helper function for CollectWithLimit. It returns
+// false once limit words have been collected so the recursion unwinds.
+func collectPersistentLimit(node *persistentNode, path []byte, limit int, words *[]string) bool {
+	if node.isEndOfWord {
+		*words = append(*words, string(path))
+		if len(*words) >= limit {
+			return false
+		}
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil {
+			if !collectPersistentLimit(child, append(path, indexToChar(i)), limit, words) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// collectPersistent is a helper function for CollectAllWordsStartingWith that performs a DFS.
+func collectPersistent(node *persistentNode, currentWord string, words *[]string) {
+	if node.isEndOfWord {
+		*words = append(*words, currentWord)
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil {
+			collectPersistent(child, currentWord+string(indexToChar(i)), words)
+		}
+	}
+}
+
+// find returns the node reached by following s from the root, or nil.
+func (t *PersistentTrie) find(s string) *persistentNode {
+	currentNode := t.root
+	for i := 0; i < len(s) && currentNode != nil; i++ {
+		currentNode = currentNode.children[charToIndex(s[i])]
+	}
+	return currentNode
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestPersistentTrieVersionsAreUnchanged(t *testing.T) {
+	rng := newTestRand(157)
+	versions := []*PersistentTrie{NewPersistentTrie()}
+	models := []map[string]int{{}}
+	for step := range 300 {
+		cur, model := versions[len(versions)-1], maps.Clone(models[len(models)-1])
+		word := randomWord(rng, 0, 5, 3)
+		var next *PersistentTrie
+		if rng.IntN(3) == 0 {
+			var ok bool
+			next, ok = cur.Delete(word)
+			if _, stored := model[word]; ok != stored {
+				t.Fatalf("step %d: Delete(%q) ok = %t, want %t", step, word, ok, stored)
+			}
+			delete(model, word)
+		} else {
+			next = cur.Insert(word)
+			model[word]++
+		}
+		versions = append(versions, next)
+		models = append(models, model)
+	}
+
+	// Every version, however old, still reports exactly what it held.
+	for v, trie := range versions {
+		want := slices.Sorted(maps.Keys(models[v]))
+		if want == nil {
+			want = []string{}
+		}
+		if got := trie.CollectAllWordsStartingWith(""); !slices.Equal(got, want) || trie.Len() != len(want) {
+			t.Fatalf("version %d holds %q (Len %d), want %q", v, got, trie.Len(), want)
+		}
+		for word, count := range models[v] {
+			if got := trie.Count(word); got != count {
+				t.Errorf("version %d: Count(%q) = %d, want %d", v, word, got, count)
+			}
+		}
+		if got := trie.StartsWith(""); got != (len(want) > 0) {
+			t.Errorf("version %d: StartsWith(\"\") = %t with %d words", v, got, len(want))
+		}
+	}
+}
+
+func TestPersistentTrieSharesUntouchedBranches(t *testing.T) {
+	v1 := NewPersistentTrie().Insert("dog").Insert("door").Insert("cat")
+	v2 := v1.Insert("car")
+	v3, _ := v2.Delete("cat")
+
+	d := charToIndex('d')
+	if v1.root.children[d] != v2.root.children[d] || v2.root.children[d] != v3.root.children[d] {
+		t.Error(`the "d" branch was copied although no version changed it`)
+	}
+	c := charToIndex('c')
+	if v1.root.children[c] == v2.root.children[c] {
+		t.Error(`the "c" branch is shared although Insert("car") changed it`)
+	}
+	if v1.root == v2.root || v2.root == v3.root {
+		t.Error("versions share a root node")
+	}
+
+	if !v1.Search("cat") || v1.Search("car") || !v2.Search("cat") || v3.Search("cat") || !v3.Search("car") {
+		t.Errorf("versions disagree: v1 %q, v2 %q, v3 %q",
+			v1.CollectAllWordsStartingWith(""), v2.CollectAllWordsStartingWith(""), v3.CollectAllWordsStartingWith(""))
+	}
+	if same, ok := v3.Delete("zebra"); ok || same != v3 {
+		t.Error(`Delete of an absent word should return the same version and false`)
+	}
+}
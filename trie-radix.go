@@ -0,0 +1,170 @@
+package main
+
+import "strings"
+
+// radixEdge is a RadixTrie edge, labelled with one or more characters.
+type radixEdge struct {
+	label string
+	node  *radixNode
+}
+
+// radixNode is a RadixTrie node. Its edges are kept sorted by the index of
+// their first character, and no two edges share a first character.
+type radixNode struct {
+	edges       []radixEdge
+	isEndOfWord bool
+}
+
+// edgeIndex returns the position of the edge starting with char, or -1.
+func (n *radixNode) edgeIndex(char byte) int {
+	for i, e := range n.edges {
+		if e.label[0] == char {
+			return i
+		}
+	}
+	return -1
+}
+
+// addEdge inserts e, keeping the edges sorted like Trie's child array.
+func (n *radixNode) addEdge(e radixEdge) {
+	idx := charToIndex(e.label[0])
+	pos := 0
+	for pos < len(n.edges) && charToIndex(n.edges[pos].label[0]) < idx {
+		pos++
+	}
+	n.edges = append(n.edges, radixEdge{})
+	copy(n.edges[pos+1:], n.edges[pos:])
+	n.edges[pos] = e
+}
+
+// RadixTrie is a compressed Trie: chains of single-child nodes are merged into
+// one edge labelled with the whole run of characters, so a long word without
+// branching costs one node instead of one per character. Edges are split when
+// an inserted word diverges partway along one. It accepts the same alphabet
+// as Trie and offers the same basic API.
+type RadixTrie struct {
+	root *radixNode
+}
+
+// NewRadixTrie creates and returns a new RadixTrie.
+func NewRadixTrie() *RadixTrie {
+	return &RadixTrie{root: &radixNode{}}
+}
+
+// Insert adds a word to the RadixTrie.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *RadixTrie) Insert(word string) {
+	for i := 0; i < len(word); i++ {
+		charToIndex(word[i]) // Panic up front rather than leave a half-split edge
+	}
+
+	currentNode, rest := t.root, word
+	for rest != "" {
+		i := currentNode.edgeIndex(rest[0])
+		if i == -1 {
+			currentNode.addEdge(radixEdge{label: rest, node: &radixNode{isEndOfWord: true}})
+			return
+		}
+		e := &currentNode.edges[i]
+		l := commonPrefixLen(e.label, rest)
+		if l < len(e.label) {
+			// rest diverges (or ends) inside the edge: split it at l, e.g.
+			// inserting "team" turns the edge "teams" into "team" -> "s".
+			mid := &radixNode{edges: []radixEdge{{label: e.label[l:], node: e.node}}}
+			e.label, e.node = e.label[:l], mid
+		}
+		currentNode, rest = e.node, rest[l:]
+	}
+	currentNode.isEndOfWord = true
+}
+
+// commonPrefixLen returns the length of the longest common prefix of a and b.
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// Search checks if a word exists in the RadixTrie.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *RadixTrie) Search(word string) bool {
+	currentNode, rest := t.root, word
+	for rest != "" {
+		i := currentNode.edgeIndex(rest[0])
+		if i == -1 || !strings.HasPrefix(rest, currentNode.edges[i].label) {
+			return false
+		}
+		rest = rest[len(currentNode.edges[i].label):]
+		currentNode = currentNode.edges[i].node
+	}
+	return currentNode.isEndOfWord
+}
+
+// StartsWith checks if there is any word in the RadixTrie that starts with the given prefix.
+// As with Trie.StartsWith, on an empty RadixTrie even the empty prefix reports false.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *RadixTrie) StartsWith(prefix string) bool {
+	node, _ := t.find(prefix)
+	// Nothing is ever deleted, so only the root of an empty trie has no word below it.
+	return node != nil && (node.isEndOfWord || len(node.edges) > 0)
+}
+
+// CollectAllWordsStartingWith collects all words that start with the given
+// prefix, in the same sorted order as Trie.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *RadixTrie) CollectAllWordsStartingWith(prefix string) []string {
+	words := []string{}
+	if node, path := t.find(prefix); node != nil {
+		collectRadix(node, path, &words)
+	}
+	return words
+}
+
+// collectRadix is a helper function for CollectAllWordsStartingWith that performs a DFS.
+func collectRadix(node *radixNode, currentWord string, words *[]string) {
+	if node.isEndOfWord {
+		*words = append(*words, currentWord)
+	}
+	for _, e := range node.edges {
+		collectRadix(e.node, currentWord+e.label, words)
+	}
+}
+
+// find returns the shallowest node whose path starts with prefix, along with
+// that path, which can run past prefix when prefix ends inside an edge.
+// node is nil if no stored word starts with prefix.
+func (t *RadixTrie) find(prefix string) (node *radixNode, path string) {
+	currentNode, rest := t.root, prefix
+	for rest != "" {
+		i := currentNode.edgeIndex(rest[0])
+		if i == -1 {
+			return nil, ""
+		}
+		label := currentNode.edges[i].label
+		switch {
+		case strings.HasPrefix(rest, label):
+			rest = rest[len(label):]
+		case strings.HasPrefix(label, rest):
+			return currentNode.edges[i].node, prefix + label[len(rest):]
+		default:
+			return nil, ""
+		}
+		currentNode = currentNode.edges[i].node
+	}
+	return currentNode, prefix
+}
+
+// NodeCount returns the number of nodes, including the root; compare with Trie.NodeCount.
+func (t *RadixTrie) NodeCount() int {
+	var count func(node *radixNode) int
+	count = func(node *radixNode) int {
+		n := 1
+		for _, e := range node.edges {
+			n += count(e.node)
+		}
+		return n
+	}
+	return count(t.root)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRadixTrieSplitsEdges(t *testing.T) {
+	rt := NewRadixTrie()
+	rt.Insert("teams")
+	if got := rt.NodeCount(); got != 2 {
+		t.Fatalf("NodeCount() with one word = %d, want 2 (root and one edge)", got)
+	}
+	rt.Insert("team") // Splits the "teams" edge at "team"
+	rt.Insert("tea")  // Splits it again at "tea"
+	rt.Insert("ten")  // Diverges partway along "tea"
+
+	for word, want := range map[string]bool{
+		"teams": true, "team": true, "tea": true, "ten": true,
+		"te": false, "t": false, "teamsx": false, "tex": false, "": false,
+	} {
+		if got := rt.Search(word); got != want {
+			t.Errorf("Search(%q) = %t, want %t", word, got, want)
+		}
+	}
+	for prefix, want := range map[string]bool{"": true, "t": true, "te": true, "teams": true, "teamx": false, "u": false} {
+		if got := rt.StartsWith(prefix); got != want {
+			t.Errorf("StartsWith(%q) = %t, want %t", prefix, got, want)
+		}
+	}
+	if got, want := rt.CollectAllWordsStartingWith("tea"), []string{"tea", "team", "teams"}; !slices.Equal(got, want) {
+		t.Errorf("CollectAllWordsStartingWith(\"tea\") = %q, want %q", got, want)
+	}
+	// root -> "te" -> "a" -> "m" -> "s", and "te" -> "n"
+	if got := rt.NodeCount(); got != 6 {
+		t.Errorf("NodeCount() = %d, want 6", got)
+	}
+}
+
+// TestRadixTrieMatchesTrie inserts random word sets, in random order, into a
+// RadixTrie and a reference Trie and checks that they answer identically.
+func TestRadixTrieMatchesTrie(t *testing.T) {
+	rng := newTestRand(270)
+	for run := range 20 {
+		words := randomWords(rng, 200, 0, 8, 3+run%4)
+		rt, ref := NewRadixTrie(), NewTrie()
+		for _, word := range words {
+			rt.Insert(word)
+			ref.Insert(word)
+		}
+
+		probes := randomWords(rng, 200, 0, 9, 3+run%4)
+		for _, word := range words {
+			probes = append(probes, word[:len(word)/2], word)
+		}
+		for _, p := range probes {
+			if got, want := rt.Search(p), ref.Search(p); got != want {
+				t.Fatalf("run %d: Search(%q) = %t, want %t", run, p, got, want)
+			}
+			if got, want := rt.StartsWith(p), ref.StartsWith(p); got != want {
+				t.Fatalf("run %d: StartsWith(%q) = %t, want %t", run, p, got, want)
+			}
+			if got, want := rt.CollectAllWordsStartingWith(p), ref.CollectAllWordsStartingWith(p); !slices.Equal(got, want) {
+				t.Fatalf("run %d: CollectAllWordsStartingWith(%q) = %q, want %q", run, p, got, want)
+			}
+		}
+		if rt.NodeCount() > ref.NodeCount() {
+			t.Errorf("run %d: RadixTrie has %d nodes, more than Trie's %d", run, rt.NodeCount(), ref.NodeCount())
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"slices"
+	"unicode/utf8"
+)
+
+// runeNode is a RuneTrie node. Its children live in a map because the rune
+// alphabet is far too large for a fixed array.
+type runeNode struct {
+	children    map[rune]*runeNode
+	isEndOfWord bool
+	wordCount   int // Number of distinct words ending at or below this node
+}
+
+// RuneTrie is a Trie over arbitrary runes rather than the fixed byte
+// alphabet: each rune of a word, multi-byte or not, is a single node. It has
+// the same method set as Trie's basics, so it can stand in for one.
+//
+// Any valid UTF-8 is accepted. Strings are not normalized, so "é" written as
+// 'e' plus U+0301 (combining acute) is a different, longer word than the
+// precomposed U+00E9. Invalid UTF-8 is rejected as a whole: Insert ignores
+// it and lookups report false, since ranging over it would otherwise turn
+// every bad byte into U+FFFD and conflate unrelated strings.
+type RuneTrie struct {
+	root *runeNode
+}
+
+// NewRuneTrie creates and returns a new RuneTrie.
+func NewRuneTrie() *RuneTrie {
+	return &RuneTrie{root: &runeNode{}}
+}
+
+// Insert adds a word to the RuneTrie.
+func (t *RuneTrie) Insert(word string) {
+	if !utf8.ValidString(word) {
+		return
+	}
+	if node := t.find(word); node != nil && node.isEndOfWord {
+		return
+	}
+	currentNode := t.root
+	currentNode.wordCount++
+	for _, r := range word {
+		if currentNode.children == nil {
+			currentNode.children = make(map[rune]*runeNode)
+		}
+		next, ok := currentNode.children[r]
+		if !ok {
+			next = &runeNode{}
+			currentNode.children[r] = next
+		}
+		currentNode = next
+		currentNode.wordCount++
+	}
+	currentNode.isEndOfWord = true
+}
+
+// Search checks if a word exists in the RuneTrie.
+func (t *RuneTrie) Search(word string) bool {
+	node := t.find(word)
+	return node != nil && node.isEndOfWord
+}
+
+// StartsWith checks if there is any word in the RuneTrie that starts with the given prefix.
+// As with Trie.StartsWith, paths left behind by Delete don't count, and on an
+// empty RuneTrie even the empty prefix reports false.
+func (t *RuneTrie) StartsWith(prefix string) bool {
+	node := t.find(prefix)
+	return node != nil && node.wordCount > 0
+}
+
+// Delete removes a word from the RuneTrie, reporting whether it was present.
+// Like Trie.Delete it is a soft delete that only unmarks the word.
+func (t *RuneTrie) Delete(word string) bool {
+	node := t.find(word)
+	if node == nil || !node.isEndOfWord {
+		return false
+	}
+	node.isEndOfWord = false
+	currentNode := t.root
+	currentNode.wordCount--
+	for _, r := range word {
+		currentNode = currentNode.children[r]
+		currentNode.wordCount--
+	}
+	return true
+}
+
+// CollectAllWordsStartingWith collects all words that start with the given
+// prefix. Like Trie's, the result is sorted: children are visited in
+// ascending rune order rather than in map order, so the output is the same on
+// every call. Ordering by rune value matches byte order for valid UTF-8.
+func (t *RuneTrie) CollectAllWordsStartingWith(prefix string) []string {
+	words := []string{}
+	if node := t.find(prefix); node != nil {
+		collectRunes(node, []rune(prefix), &words)
+	}
+	return words
+}
+
+// collectRunes is a helper function for CollectAllWordsStartingWith that performs a DFS.
+func collectRunes(node *runeNode, path []rune, words *[]string) {
+	if node.isEndOfWord {
+		*words = append(*words, string(path))
+	}
+	for _, r := range sortedRunes(node.children) {
+		collectRunes(node.children[r], append(path, r), words)
+	}
+}
+
+// sortedRunes returns the keys of children in ascending order.
+func sortedRunes(children map[rune]*runeNode) []rune {
+	keys := make([]rune, 0, len(children))
+	for r := range children {
+		keys = append(keys, r)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// find returns the node reached by following s from the root, or nil. It is
+// nil for invalid UTF-8 too.
+func (t *RuneTrie) find(s string) *runeNode {
+	if !utf8.ValidString(s) {
+		return nil
+	}
+	currentNode := t.root
+	for _, r := range s {
+		currentNode = currentNode.children[r] // Indexing a nil map is fine
+		if currentNode == nil {
+			return nil
+		}
+	}
+	return currentNode
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestRuneTrieCollectSortedByRune inserts ASCII and non-ASCII words in a
+// different order on every run and checks that collection order, which comes
+// from map-based children, never depends on map iteration.
+func TestRuneTrieCollectSortedByRune(t *testing.T) {
+	words := []string{
+		"zebra", "Zebra", "école", "ecole", "éclair", "über", "uber", "naïve", "naive",
+		"日本", "日本語", "中国", "中", "Ωmega", "omega", "ñandú", "nandu", "a", "", "ä",
+	}
+	want := slices.Clone(words)
+	slices.SortFunc(want, func(a, b string) int { return slices.Compare([]rune(a), []rune(b)) })
+
+	rng := newTestRand(165)
+	for run := range 50 {
+		rng.Shuffle(len(words), func(i, j int) { words[i], words[j] = words[j], words[i] })
+		trie := NewRuneTrie()
+		for _, word := range words {
+			trie.Insert(word)
+		}
+		if got := trie.CollectAllWordsStartingWith(""); !slices.Equal(got, want) {
+			t.Fatalf("run %d: CollectAllWordsStartingWith(\"\") = %q, want %q", run, got, want)
+		}
+		if got, want := trie.CollectAllWordsStartingWith("日本"), []string{"日本", "日本語"}; !slices.Equal(got, want) {
+			t.Fatalf("run %d: CollectAllWordsStartingWith(\"日本\") = %q, want %q", run, got, want)
+		}
+	}
+}
+
+// TestRuneTrieUnicodeWords covers multi-byte runes: each rune is one node, so
+// a prefix that ends halfway through a rune's bytes matches nothing, and
+// combining accents stay distinct from their precomposed forms.
+func TestRuneTrieUnicodeWords(t *testing.T) {
+	trie := NewRuneTrie()
+	for _, word := range []string{"привет", "пример", "🍎", "🍎🍐", "café", "naïve"} {
+		trie.Insert(word)
+	}
+
+	tests := []struct {
+		word       string
+		search     bool
+		startsWith bool
+	}{
+		{"привет", true, true},
+		{"при", false, true},
+		{"прив", false, true},
+		{"пр\xd0", false, false},
+		{"🍎", true, true},
+		{"🍎🍐", true, true},
+		{"\xf0\x9f", false, false},
+		{"café", true, true},
+		{"cafe", false, true},
+		{"café", false, false},
+		{"naïve", true, true},
+		{"naive", false, false},
+	}
+	for _, tt := range tests {
+		if got := trie.Search(tt.word); got != tt.search {
+			t.Errorf("Search(%q) = %v, want %v", tt.word, got, tt.search)
+		}
+		if got := trie.StartsWith(tt.word); got != tt.startsWith {
+			t.Errorf("StartsWith(%q) = %v, want %v", tt.word, got, tt.startsWith)
+		}
+	}
+
+	if got, want := trie.CollectAllWordsStartingWith("пр"), []string{"привет", "пример"}; !slices.Equal(got, want) {
+		t.Errorf("CollectAllWordsStartingWith(\"пр\") = %q, want %q", got, want)
+	}
+	if got, want := trie.CollectAllWordsStartingWith("🍎"), []string{"🍎", "🍎🍐"}; !slices.Equal(got, want) {
+		t.Errorf("CollectAllWordsStartingWith(\"🍎\") = %q, want %q", got, want)
+	}
+	if !trie.Delete("🍎") || trie.Search("🍎") || !trie.Search("🍎🍐") {
+		t.Error("Delete(\"🍎\") should remove only that word")
+	}
+}
+
+// TestRuneTrieRejectsInvalidUTF8 checks that invalid UTF-8 is neither stored
+// nor conflated with U+FFFD.
+func TestRuneTrieRejectsInvalidUTF8(t *testing.T) {
+	trie := NewRuneTrie()
+	trie.Insert("a\xffb")
+	if got := trie.CollectAllWordsStartingWith(""); len(got) != 0 {
+		t.Fatalf("invalid insert stored %q", got)
+	}
+
+	trie.Insert("a�b")
+	if trie.Search("a\xffb") || trie.StartsWith("a\xff") {
+		t.Error("invalid UTF-8 lookup matched a U+FFFD word")
+	}
+	if !trie.Search("a�b") {
+		t.Error("Search(\"a\\uFFFDb\") = false, want true")
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"sort"
+	"sync"
+)
+
+// trieShard is one independently locked sub-trie of a ShardedTrie.
+type trieShard struct {
+	mu   sync.RWMutex
+	trie *Trie
+}
+
+// ShardedTrie is a goroutine-safe Trie split into shards by the first character
+// of each word, each shard with its own lock, so writers touching different
+// first letters don't contend. Every non-empty word or prefix lives in exactly
+// one shard; only queries for the empty prefix have to visit all of them.
+type ShardedTrie struct {
+	shards []*trieShard
+}
+
+// NewShardedTrie creates a ShardedTrie with n shards. Since words are routed by
+// their first character, more than alphabetSize shards brings no benefit.
+// It panics if n is not positive.
+func NewShardedTrie(n int) *ShardedTrie {
+	if n <= 0 {
+		panic("trie: ShardedTrie needs at least one shard")
+	}
+	shards := make([]*trieShard, n)
+	for i := range shards {
+		shards[i] = &trieShard{trie: NewTrie()}
+	}
+	return &ShardedTrie{shards: shards}
+}
+
+// shardFor returns the shard responsible for words starting like s.
+// The empty string (and so the empty word) belongs to shard 0.
+func (st *ShardedTrie) shardFor(s string) *trieShard {
+	if s == "" {
+		return st.shards[0]
+	}
+	return st.shards[charToIndex(s[0])%len(st.shards)]
+}
+
+// Insert adds a word to the shard owning its first character.
+// Assumes input 'word' contains only lowercase English letters.
+func (st *ShardedTrie) Insert(word string) {
+	shard := st.shardFor(word)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	shard.trie.Insert(word)
+}
+
+// Delete removes a word, reporting whether it was present.
+// Assumes input 'word' contains only lowercase English letters.
+func (st *ShardedTrie) Delete(word string) bool {
+	shard := st.shardFor(word)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	return shard.trie.Delete(word)
+}
+
+// Search checks if a word exists in the ShardedTrie.
+// Assumes input 'word' contains only lowercase English letters.
+func (st *ShardedTrie) Search(word string) bool {
+	shard := st.shardFor(word)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+	return shard.trie.Search(word)
+}
+
+// StartsWith checks if any stored word starts with prefix.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (st *ShardedTrie) StartsWith(prefix string) bool {
+	if prefix == "" {
+		for _, shard := range st.shards { // True as soon as any shard holds a word
+			shard.mu.RLock()
+			found := shard.trie.StartsWith("")
+			shard.mu.RUnlock()
+			if found {
+				return true
+			}
+		}
+		return false
+	}
+	shard := st.shardFor(prefix)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+	return shard.trie.StartsWith(prefix)
+}
+
+// CollectAllWordsStartingWith returns the sorted words starting with prefix.
+// A non-empty prefix is answered by a single shard; the empty prefix merges
+// the results of every shard.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (st *ShardedTrie) CollectAllWordsStartingWith(prefix string) []string {
+	if prefix != "" {
+		shard := st.shardFor(prefix)
+		shard.mu.RLock()
+		defer shard.mu.RUnlock()
+		return shard.trie.CollectAllWordsStartingWith(prefix)
+	}
+
+	words := []string{}
+	for _, shard := range st.shards {
+		shard.mu.RLock()
+		words = append(words, shard.trie.CollectAllWordsStartingWith("")...)
+		shard.mu.RUnlock()
+	}
+	sort.Strings(words) // Each shard is sorted, but shards interleave first letters
+	return words
+}
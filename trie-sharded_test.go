@@ -0,0 +1,73 @@
+package main
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+// TestShardedTrieConcurrentWriters inserts from many goroutines at once; run
+// it with -race to check the per-shard locking.
+func TestShardedTrieConcurrentWriters(t *testing.T) {
+	words := loadWords(t, "words.txt")
+	st := NewShardedTrie(8)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := g; i < len(words); i += 8 {
+				st.Insert(words[i])
+				st.Search(words[(i+1)%len(words)])
+				st.StartsWith(words[i][:1])
+			}
+		}()
+	}
+	wg.Wait()
+
+	want := newTrieWith(words...)
+	if got := st.CollectAllWordsStartingWith(""); !slices.Equal(got, want.Words()) {
+		t.Errorf("CollectAllWordsStartingWith(\"\") has %d words, want %d", len(got), want.CountWords())
+	}
+	for _, prefix := range []string{"a", "ca", "th", "zz"} {
+		if got, want := st.CollectAllWordsStartingWith(prefix), want.CollectAllWordsStartingWith(prefix); !slices.Equal(got, want) {
+			t.Errorf("CollectAllWordsStartingWith(%q) = %q, want %q", prefix, got, want)
+		}
+	}
+}
+
+// lockedTrie is the subset of methods BenchmarkLockedTries exercises.
+type lockedTrie interface {
+	Insert(word string)
+	Search(word string) bool
+}
+
+// BenchmarkLockedTries compares the single-lock ConcurrentTrie with
+// ShardedTrie under parallel writers (one in four operations is a Search).
+// Run with -race -cpu 1,4,8 to see how each scales with contention.
+func BenchmarkLockedTries(b *testing.B) {
+	words := loadWords(b, "words.txt")
+	for _, bm := range []struct {
+		name string
+		new  func() lockedTrie
+	}{
+		{"ConcurrentTrie", func() lockedTrie { return NewConcurrentTrie() }},
+		{"ShardedTrie4", func() lockedTrie { return NewShardedTrie(4) }},
+		{"ShardedTrie26", func() lockedTrie { return NewShardedTrie(alphabetSize) }},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			trie := bm.new()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					word := words[i%len(words)]
+					if i%4 == 0 {
+						trie.Search(word)
+					} else {
+						trie.Insert(word)
+					}
+				}
+			})
+		})
+	}
+}
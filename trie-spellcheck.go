@@ -0,0 +1,123 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// SpellChecker checks words against a dictionary learned from text and
+// suggests corrections, favouring candidates that are both close to the typed
+// word and common. Frequencies are the insertion counts of the underlying Trie.
+//
+// A candidate at edit distance d with frequency f scores
+//
+//	d*distanceWeight - log(f)*frequencyWeight
+//
+// and lower scores rank first. With the default weights (1 and 0.5) one edit
+// is worth a factor of e² ≈ 7.4 in frequency.
+type SpellChecker struct {
+	trie            *Trie
+	maxDistance     int
+	distanceWeight  float64
+	frequencyWeight float64
+}
+
+// SpellOption configures a SpellChecker in NewSpellChecker.
+type SpellOption func(*SpellChecker)
+
+// WithMaxEdits sets the largest edit distance a correction may have (default 2).
+func WithMaxEdits(n int) SpellOption {
+	return func(s *SpellChecker) {
+		s.maxDistance = n
+	}
+}
+
+// WithWeights sets how much each edit costs and how much log-frequency earns back.
+func WithWeights(distanceWeight, frequencyWeight float64) SpellOption {
+	return func(s *SpellChecker) {
+		s.distanceWeight = distanceWeight
+		s.frequencyWeight = frequencyWeight
+	}
+}
+
+// NewSpellChecker creates a SpellChecker with an empty dictionary.
+func NewSpellChecker(opts ...SpellOption) *SpellChecker {
+	s := &SpellChecker{
+		trie:            NewTrie(WithInputPolicy(RejectInvalid)),
+		maxDistance:     2,
+		distanceWeight:  1,
+		frequencyWeight: 0.5,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Learn adds one occurrence of word to the dictionary. Words are lowercased;
+// words with characters outside the Trie alphabet are ignored.
+func (s *SpellChecker) Learn(word string) {
+	s.trie.Insert(strings.ToLower(word))
+}
+
+// LearnText adds every word of text to the dictionary, as Trie.InsertText splits it.
+func (s *SpellChecker) LearnText(text string) {
+	s.trie.InsertText(text)
+}
+
+// Check reports whether word, lowercased, is in the dictionary.
+func (s *SpellChecker) Check(word string) bool {
+	return s.trie.Search(strings.ToLower(word))
+}
+
+// Corrections returns up to limit dictionary words within the maximum edit
+// distance of word (Damerau-Levenshtein), best score first; ties are broken
+// alphabetically. A correctly spelled word is its own best candidate at
+// distance 0 unless a far more frequent neighbour outweighs it.
+func (s *SpellChecker) Corrections(word string, limit int) []string {
+	if limit <= 0 {
+		return []string{}
+	}
+	word = strings.ToLower(word)
+
+	type candidate struct {
+		word  string
+		score float64
+	}
+	var candidates []candidate
+	for _, c := range s.trie.SearchWithinDamerauDistance(word, s.maxDistance) {
+		dist := damerauDistance(word, c)
+		freq := s.trie.Count(c)
+		score := float64(dist)*s.distanceWeight - math.Log(float64(freq))*s.frequencyWeight
+		candidates = append(candidates, candidate{c, score})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score < candidates[j].score
+		}
+		return candidates[i].word < candidates[j].word
+	})
+
+	words := make([]string, 0, min(limit, len(candidates)))
+	for i := 0; i < len(candidates) && i < limit; i++ {
+		words = append(words, candidates[i].word)
+	}
+	return words
+}
+
+// damerauDistance returns the optimal string alignment distance between a
+// and b, built row by row with the same recurrence the Trie searches use.
+func damerauDistance(a, b string) int {
+	row := make([]int, len(a)+1)
+	for j := range row {
+		row[j] = j
+	}
+	var prevRow []int
+	var prevChar byte
+	for i := 0; i < len(b); i++ {
+		row, prevRow = damerauRow(a, b[i], prevChar, row, prevRow), row
+		prevChar = b[i]
+	}
+	return row[len(a)]
+}
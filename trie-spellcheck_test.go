@@ -0,0 +1,58 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSpellCheckerCorrections(t *testing.T) {
+	s := NewSpellChecker()
+	s.LearnText("The cat sat on the mat. The cat ate the rat, and the cat napped.")
+	s.Learn("cost") // Rare, but as close to "cst" as "cat" is
+	s.Learn("Bat")
+	s.Learn("b@d") // Not in the alphabet, ignored
+
+	if !s.Check("cat") || !s.Check("CAT") || !s.Check("bat") {
+		t.Error("Check rejected a learned word")
+	}
+	if s.Check("cst") || s.Check("b@d") || s.Check("bd") {
+		t.Error("Check accepted a word that was never learned")
+	}
+
+	// "cat" (learned 3 times) and "cost" (once) are both one edit from "cst".
+	if got := s.Corrections("cst", 2); !slices.Equal(got, []string{"cat", "cost"}) {
+		t.Errorf(`Corrections("cst", 2) = %q, want the common "cat" before "cost"`, got)
+	}
+	if got := s.Corrections("cost", 1); !slices.Equal(got, []string{"cost"}) {
+		t.Errorf(`Corrections("cost", 1) = %q, want the word itself`, got)
+	}
+	if got := s.Corrections("zzzzzz", 5); got == nil || len(got) != 0 {
+		t.Errorf(`Corrections("zzzzzz") = %q, want []`, got)
+	}
+	if got := s.Corrections("cat", 0); got == nil || len(got) != 0 {
+		t.Errorf(`Corrections("cat", 0) = %q, want []`, got)
+	}
+}
+
+func TestSpellCheckerWeights(t *testing.T) {
+	learn := func(s *SpellChecker) *SpellChecker {
+		for range 50 {
+			s.Learn("there") // Two edits from "thier", but common
+		}
+		s.Learn("thief") // One edit, rare
+		return s
+	}
+
+	// By default one edit is worth a factor of about 7.4 in frequency, so 50
+	// occurrences outweigh the extra edit.
+	if got := learn(NewSpellChecker()).Corrections("thier", 2); !slices.Equal(got, []string{"there", "thief"}) {
+		t.Errorf("default weights: Corrections(%q) = %q, want [there thief]", "thier", got)
+	}
+	// Ignoring frequency leaves pure edit distance.
+	if got := learn(NewSpellChecker(WithWeights(1, 0))).Corrections("thier", 2); !slices.Equal(got, []string{"thief", "there"}) {
+		t.Errorf("distance only: Corrections(%q) = %q, want [thief there]", "thier", got)
+	}
+	if got := learn(NewSpellChecker(WithMaxEdits(1))).Corrections("thier", 5); !slices.Equal(got, []string{"thief"}) {
+		t.Errorf("WithMaxEdits(1): Corrections(%q) = %q, want [thief]", "thier", got)
+	}
+}
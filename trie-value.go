@@ -0,0 +1,191 @@
+package main
+
+// valueNode represents a node in a ValueTrie.
+type valueNode[V any] struct {
+	children    [alphabetSize]*valueNode[V]
+	isEndOfWord bool // True if this node marks the end of a word
+	value       V    // Value stored for the word ending here; zero value otherwise
+}
+
+// ValueTrie is a Trie that maps each stored word to a value of type V,
+// behaving like a prefix-aware map[string]V. It has no input policy: every
+// method panics with an InvalidCharError on characters outside the alphabet,
+// before changing anything.
+type ValueTrie[V any] struct {
+	root *valueNode[V]
+}
+
+// NewValueTrie creates and returns a new ValueTrie.
+func NewValueTrie[V any]() *ValueTrie[V] {
+	return &ValueTrie[V]{
+		root: &valueNode[V]{},
+	}
+}
+
+// Insert stores value under word, overwriting any existing value.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *ValueTrie[V]) Insert(word string, value V) {
+	mustBeInAlphabet(word)
+	currentNode := t.root
+	for i := 0; i < len(word); i++ {
+		idx := charToIndex(word[i])
+		if currentNode.children[idx] == nil {
+			currentNode.children[idx] = &valueNode[V]{}
+		}
+		currentNode = currentNode.children[idx]
+	}
+	currentNode.isEndOfWord = true
+	currentNode.value = value
+}
+
+// Get returns the value stored under word and whether word is present.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *ValueTrie[V]) Get(word string) (V, bool) {
+	node := t.find(word)
+	if node == nil || !node.isEndOfWord {
+		var zero V
+		return zero, false
+	}
+	return node.value, true
+}
+
+// Update replaces the value stored under word with fn(old), in place.
+// It returns false, without calling fn, if word is not present.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *ValueTrie[V]) Update(word string, fn func(old V) V) bool {
+	node := t.find(word)
+	if node == nil || !node.isEndOfWord {
+		return false
+	}
+	node.value = fn(node.value)
+	return true
+}
+
+// Delete removes word and clears its value (soft delete, like Trie.Delete).
+// Assumes input 'word' contains only lowercase English letters.
+func (t *ValueTrie[V]) Delete(word string) bool {
+	node := t.find(word)
+	if node == nil || !node.isEndOfWord {
+		return false
+	}
+	var zero V
+	node.isEndOfWord = false
+	node.value = zero // Don't keep the value reachable after deletion
+	return true
+}
+
+// Rename moves the value stored under oldWord to newWord, overwriting any value
+// newWord already had, and removes oldWord. It returns false, changing nothing,
+// if oldWord is not present. Unlike Trie, ValueTrie keeps no insertion count:
+// Insert overwrites, so a word is stored once and only its value moves. A
+// caller that counts words can keep the count inside V.
+// Assumes both words contain only lowercase English letters.
+func (t *ValueTrie[V]) Rename(oldWord, newWord string) bool {
+	value, ok := t.Get(oldWord)
+	if !ok {
+		return false
+	}
+	if oldWord == newWord {
+		return true
+	}
+	mustBeInAlphabet(newWord) // Panic before deleting oldWord, not halfway through
+	t.Delete(oldWord)
+	t.Insert(newWord, value)
+	return true
+}
+
+// WordValue is a stored word together with its value, as returned by CollectWithValues.
+type WordValue[V any] struct {
+	Word  string
+	Value V
+}
+
+// CollectAllWordsStartingWith collects all words that start with the given prefix, in sorted order.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *ValueTrie[V]) CollectAllWordsStartingWith(prefix string) []string {
+	words := []string{}
+	if node := t.find(prefix); node != nil {
+		t.walk(node, prefix, func(word string, _ V) { words = append(words, word) })
+	}
+	return words
+}
+
+// CollectWithValues is CollectAllWordsStartingWith that also returns each word's value.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *ValueTrie[V]) CollectWithValues(prefix string) []WordValue[V] {
+	entries := []WordValue[V]{}
+	if node := t.find(prefix); node != nil {
+		t.walk(node, prefix, func(word string, value V) {
+			entries = append(entries, WordValue[V]{Word: word, Value: value})
+		})
+	}
+	return entries
+}
+
+// find returns the node reached by following word from the root, or nil.
+// It panics if word contains a character outside the alphabet.
+func (t *ValueTrie[V]) find(word string) *valueNode[V] {
+	mustBeInAlphabet(word)
+	currentNode := t.root
+	for i := 0; i < len(word); i++ {
+		idx := charToIndex(word[i])
+		if currentNode.children[idx] == nil {
+			return nil
+		}
+		currentNode = currentNode.children[idx]
+	}
+	return currentNode
+}
+
+// WordsForValue returns, in sorted order, every word in t whose value equals v.
+// It is a function rather than a method because it needs V to be comparable,
+// which ValueTrie itself doesn't require. It scans the whole Trie.
+func WordsForValue[V comparable](t *ValueTrie[V], v V) []string {
+	words := []string{}
+	t.walk(t.root, "", func(word string, value V) {
+		if value == v {
+			words = append(words, word)
+		}
+	})
+	return words
+}
+
+// walk calls fn for every word stored at or below node, in sorted order.
+func (t *ValueTrie[V]) walk(node *valueNode[V], currentWord string, fn func(word string, value V)) {
+	if node.isEndOfWord {
+		fn(currentWord, node.value)
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil {
+			t.walk(child, currentWord+string(indexToChar(i)), fn)
+		}
+	}
+}
+
+// EqualValues reports whether a and b store the same words with equal (==)
+// values for each. Like WordsForValue it is a function because it needs V to
+// be comparable. Nodes left behind by deletes don't affect the result.
+func EqualValues[V comparable](a, b *ValueTrie[V]) bool {
+	type entry struct {
+		word  string
+		value V
+	}
+	var entries []entry
+	a.walk(a.root, "", func(word string, value V) {
+		entries = append(entries, entry{word, value})
+	})
+
+	// Both walks are sorted, so compare b's entries against a's in lockstep.
+	i, equal := 0, true
+	b.walk(b.root, "", func(word string, value V) {
+		if !equal {
+			return
+		}
+		if i >= len(entries) || entries[i].word != word || entries[i].value != value {
+			equal = false
+			return
+		}
+		i++
+	})
+	return equal && i == len(entries)
+}
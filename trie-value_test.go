@@ -0,0 +1,237 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+type wordMeta struct {
+	hits     int
+	lastSeen time.Time
+	tags     []string
+}
+
+func TestValueTrieUpdate(t *testing.T) {
+	vt := NewValueTrie[wordMeta]()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	vt.Insert("go", wordMeta{lastSeen: start})
+	vt.Insert("gopher", wordMeta{tags: []string{"animal"}})
+
+	for i := range 3 {
+		ok := vt.Update("go", func(old wordMeta) wordMeta {
+			old.hits++
+			old.lastSeen = start.Add(time.Duration(i+1) * time.Hour)
+			return old
+		})
+		if !ok {
+			t.Fatalf(`Update("go") = false on call %d, want true`, i)
+		}
+	}
+	vt.Update("gopher", func(old wordMeta) wordMeta {
+		old.tags = append(old.tags, "mascot")
+		return old
+	})
+
+	got, ok := vt.Get("go")
+	if !ok || got.hits != 3 || !got.lastSeen.Equal(start.Add(3*time.Hour)) {
+		t.Errorf(`Get("go") = %+v, %t; want 3 hits last seen at 03:00`, got, ok)
+	}
+	if got, _ := vt.Get("gopher"); !slices.Equal(got.tags, []string{"animal", "mascot"}) {
+		t.Errorf(`Get("gopher").tags = %q, want [animal mascot]`, got.tags)
+	}
+
+	called := false
+	fn := func(old wordMeta) wordMeta { called = true; return old }
+	for _, word := range []string{"gop", "gophers", ""} {
+		if vt.Update(word, fn) {
+			t.Errorf("Update(%q) = true for a word that isn't stored", word)
+		}
+	}
+	vt.Delete("go")
+	if vt.Update("go", fn) {
+		t.Error(`Update("go") after Delete = true, want false`)
+	}
+	if called {
+		t.Error("Update called fn for a word that isn't stored")
+	}
+}
+
+func TestWordsForValue(t *testing.T) {
+	vt := NewValueTrie[string]()
+	for word, value := range map[string]string{
+		"cat": "animal", "dog": "animal", "cow": "animal",
+		"car": "vehicle", "bus": "vehicle",
+	} {
+		vt.Insert(word, value)
+	}
+	if got, want := WordsForValue(vt, "animal"), []string{"cat", "cow", "dog"}; !slices.Equal(got, want) {
+		t.Errorf(`WordsForValue("animal") = %q, want %q`, got, want)
+	}
+
+	vt.Insert("cow", "vehicle") // Overwrite moves cow to the other group
+	vt.Delete("bus")
+	if got, want := WordsForValue(vt, "animal"), []string{"cat", "dog"}; !slices.Equal(got, want) {
+		t.Errorf(`WordsForValue("animal") after overwrite = %q, want %q`, got, want)
+	}
+	if got, want := WordsForValue(vt, "vehicle"), []string{"car", "cow"}; !slices.Equal(got, want) {
+		t.Errorf(`WordsForValue("vehicle") after overwrite = %q, want %q`, got, want)
+	}
+	if got := WordsForValue(vt, "plant"); got == nil || len(got) != 0 {
+		t.Errorf(`WordsForValue("plant") = %#v, want an empty, non-nil slice`, got)
+	}
+}
+
+func TestValueTrieRename(t *testing.T) {
+	vt := NewValueTrie[wordMeta]()
+	vt.Insert("colour", wordMeta{hits: 4, tags: []string{"uk"}})
+	vt.Insert("col", wordMeta{hits: 1})
+	vt.Insert("flavour", wordMeta{hits: 2})
+	vt.Insert("flavor", wordMeta{hits: 9})
+
+	if !vt.Rename("colour", "color") {
+		t.Fatal(`Rename("colour", "color") = false, want true`)
+	}
+	if _, ok := vt.Get("colour"); ok {
+		t.Error(`Get("colour") after Rename still finds it`)
+	}
+	if got, ok := vt.Get("color"); !ok || got.hits != 4 || !slices.Equal(got.tags, []string{"uk"}) {
+		t.Errorf(`Get("color") = %+v, %t; want the value moved from "colour"`, got, ok)
+	}
+	if got, _ := vt.Get("col"); got.hits != 1 {
+		t.Errorf(`Get("col") = %+v, want it untouched`, got)
+	}
+
+	// Renaming onto an existing word overwrites it.
+	vt.Rename("flavour", "flavor")
+	if got, _ := vt.Get("flavor"); got.hits != 2 {
+		t.Errorf(`Get("flavor") = %+v, want the value from "flavour"`, got)
+	}
+	if got, want := vt.CollectAllWordsStartingWith(""), []string{"col", "color", "flavor"}; !slices.Equal(got, want) {
+		t.Errorf("words after renames = %q, want %q", got, want)
+	}
+
+	if vt.Rename("colour", "hue") || vt.Rename("co", "hue") {
+		t.Error("Rename of a missing word = true, want false")
+	}
+	if _, ok := vt.Get("hue"); ok {
+		t.Error(`a failed Rename inserted "hue"`)
+	}
+	if !vt.Rename("col", "col") {
+		t.Error(`Rename("col", "col") = false, want true`)
+	}
+	if got, ok := vt.Get("col"); !ok || got.hits != 1 {
+		t.Errorf(`Get("col") after renaming onto itself = %+v, %t`, got, ok)
+	}
+}
+
+func TestEqualValues(t *testing.T) {
+	build := func(entries map[string]int) *ValueTrie[int] {
+		vt := NewValueTrie[int]()
+		for word, value := range entries {
+			vt.Insert(word, value)
+		}
+		return vt
+	}
+	base := map[string]int{"a": 1, "ab": 2, "b": 3}
+	tests := []struct {
+		name  string
+		other map[string]int
+		want  bool
+	}{
+		{"identical", map[string]int{"a": 1, "ab": 2, "b": 3}, true},
+		{"same words, one value differs", map[string]int{"a": 1, "ab": 20, "b": 3}, false},
+		{"same values, one word differs", map[string]int{"a": 1, "ac": 2, "b": 3}, false},
+		{"extra word", map[string]int{"a": 1, "ab": 2, "b": 3, "c": 4}, false},
+		{"missing word", map[string]int{"a": 1, "b": 3}, false},
+		{"empty", nil, false},
+	}
+	for _, tt := range tests {
+		a, b := build(base), build(tt.other)
+		if got := EqualValues(a, b); got != tt.want {
+			t.Errorf("%s: EqualValues(a, b) = %t, want %t", tt.name, got, tt.want)
+		}
+		if got := EqualValues(b, a); got != tt.want {
+			t.Errorf("%s: EqualValues(b, a) = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+
+	// Deleted words and the nodes they leave behind don't matter.
+	a, b := build(base), build(base)
+	a.Insert("abc", 9)
+	a.Delete("abc")
+	if !EqualValues(a, b) {
+		t.Error("EqualValues = false after inserting and deleting an extra word")
+	}
+	if !EqualValues(NewValueTrie[int](), NewValueTrie[int]()) {
+		t.Error("two empty ValueTries are not EqualValues")
+	}
+}
+
+func TestValueTrieInts(t *testing.T) {
+	vt := NewValueTrie[int]()
+	vt.Insert("one", 1)
+	vt.Insert("on", 0)
+	vt.Insert("only", 4)
+	vt.Insert("one", 11) // Overwrites rather than duplicating
+
+	if got, ok := vt.Get("one"); !ok || got != 11 {
+		t.Errorf(`Get("one") = %d, %t; want 11, true`, got, ok)
+	}
+	if got, ok := vt.Get("on"); !ok || got != 0 {
+		t.Errorf(`Get("on") = %d, %t; want 0, true (a stored zero value)`, got, ok)
+	}
+	if got, ok := vt.Get("o"); ok || got != 0 {
+		t.Errorf(`Get("o") = %d, %t; want 0, false (prefix only)`, got, ok)
+	}
+	want := []WordValue[int]{{"on", 0}, {"one", 11}, {"only", 4}}
+	if got := vt.CollectWithValues("on"); !slices.Equal(got, want) {
+		t.Errorf(`CollectWithValues("on") = %v, want %v`, got, want)
+	}
+
+	if !vt.Delete("one") {
+		t.Fatal(`Delete("one") = false, want true`)
+	}
+	if vt.Delete("one") {
+		t.Error(`second Delete("one") = true, want false`)
+	}
+	if got, ok := vt.Get("one"); ok || got != 0 {
+		t.Errorf(`Get("one") after Delete = %d, %t; want 0, false`, got, ok)
+	}
+	want = []WordValue[int]{{"on", 0}, {"only", 4}}
+	if got := vt.CollectWithValues(""); !slices.Equal(got, want) {
+		t.Errorf(`CollectWithValues("") after Delete = %v, want %v`, got, want)
+	}
+
+	vt.Insert("one", 7)
+	if got, _ := vt.Get("one"); got != 7 {
+		t.Errorf(`Get("one") after re-insert = %d, want 7`, got)
+	}
+}
+
+func TestValueTrieStructs(t *testing.T) {
+	vt := NewValueTrie[wordMeta]()
+	vt.Insert("go", wordMeta{hits: 1, tags: []string{"lang"}})
+	vt.Insert("gopher", wordMeta{hits: 2})
+	vt.Insert("go", wordMeta{hits: 5, tags: []string{"verb"}})
+
+	got, ok := vt.Get("go")
+	if !ok || got.hits != 5 || !slices.Equal(got.tags, []string{"verb"}) {
+		t.Errorf(`Get("go") = %+v, %t; want the overwritten value`, got, ok)
+	}
+	entries := vt.CollectWithValues("go")
+	if len(entries) != 2 || entries[0].Word != "go" || entries[1].Word != "gopher" || entries[1].Value.hits != 2 {
+		t.Errorf(`CollectWithValues("go") = %+v, want go then gopher with their values`, entries)
+	}
+
+	vt.Delete("go")
+	if got, ok := vt.Get("go"); ok || got.hits != 0 || got.tags != nil {
+		t.Errorf(`Get("go") after Delete = %+v, %t; want the zero value, false`, got, ok)
+	}
+	if node := vt.find("go"); node == nil || node.value.tags != nil {
+		t.Error(`Delete("go") left the old value in the node`)
+	}
+	if got, ok := vt.Get("gopher"); !ok || got.hits != 2 {
+		t.Errorf(`Get("gopher") = %+v, %t; want it untouched by Delete("go")`, got, ok)
+	}
+}
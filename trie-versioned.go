@@ -0,0 +1,66 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// VersionedTrie is a goroutine-safe Trie built on PersistentTrie. Writers are
+// serialized by a mutex and publish each new version with an atomic store;
+// readers just load the current version and never block, even while a write
+// is in progress. A reader that holds on to Snapshot sees a frozen version.
+type VersionedTrie struct {
+	mu      sync.Mutex // Serializes writers only
+	current atomic.Pointer[PersistentTrie]
+}
+
+// NewVersionedTrie creates an empty VersionedTrie.
+func NewVersionedTrie() *VersionedTrie {
+	v := &VersionedTrie{}
+	v.current.Store(NewPersistentTrie())
+	return v
+}
+
+// Snapshot returns the current version. It stays valid and unchanged no matter
+// what is written afterwards.
+func (v *VersionedTrie) Snapshot() *PersistentTrie {
+	return v.current.Load()
+}
+
+// Insert adds word, or bumps its count if present, and publishes the new version.
+// Assumes input 'word' contains only lowercase English letters.
+func (v *VersionedTrie) Insert(word string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.current.Store(v.current.Load().Insert(word))
+}
+
+// Delete removes word, reporting whether it was present.
+// Assumes input 'word' contains only lowercase English letters.
+func (v *VersionedTrie) Delete(word string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	next, ok := v.current.Load().Delete(word)
+	v.current.Store(next)
+	return ok
+}
+
+// Search checks if a word exists in the current version.
+// Assumes input 'word' contains only lowercase English letters.
+func (v *VersionedTrie) Search(word string) bool {
+	return v.Snapshot().Search(word)
+}
+
+// StartsWith checks if any word in the current version starts with prefix.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (v *VersionedTrie) StartsWith(prefix string) bool {
+	return v.Snapshot().StartsWith(prefix)
+}
+
+// Complete returns the top k completions of prefix from the current version:
+// the most frequently inserted words first, ties in alphabetical order (see
+// PersistentTrie.Suggest).
+// Assumes input 'prefix' contains only lowercase English letters.
+func (v *VersionedTrie) Complete(prefix string, k int) []string {
+	return v.Snapshot().Suggest(prefix, k)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"LeetCode/example/autocomplete"
+)
+
+// newAutocompleteTrie returns a VersionedTrie where "cat" is the most
+// frequent completion of "ca", then "car", then the rest alphabetically.
+func newAutocompleteTrie() *VersionedTrie {
+	v := NewVersionedTrie()
+	for _, word := range []string{"car", "card", "care", "cat", "cat", "cat", "car", "dog"} {
+		v.Insert(word)
+	}
+	return v
+}
+
+// TestVersionedTrieServesAutocomplete checks that VersionedTrie plugs into
+// the example autocomplete handler and ranks completions by insertion count.
+func TestVersionedTrieServesAutocomplete(t *testing.T) {
+	handler := autocomplete.NewMux(newAutocompleteTrie())
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"/complete?q=ca&k=2", []string{"cat", "car"}},
+		{"/complete?q=ca", []string{"cat", "car", "card", "care"}}, // Ties alphabetical
+		{"/complete?q=do&k=100", []string{"dog"}},
+		{"/complete?q=zz", []string{}},
+		{"/complete?k=1", []string{"cat"}},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status = %d (body %q)", tt.target, rec.Code, rec.Body.String())
+		}
+		var resp struct{ Completions []string }
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("GET %s: decoding %q: %v", tt.target, rec.Body.String(), err)
+		}
+		if !slices.Equal(resp.Completions, tt.want) {
+			t.Errorf("GET %s: completions = %q, want %q", tt.target, resp.Completions, tt.want)
+		}
+	}
+}
+
+func TestVersionedTrieCompleteSeesNewCounts(t *testing.T) {
+	v := newAutocompleteTrie()
+	before := v.Snapshot()
+	for range 5 {
+		v.Insert("care")
+	}
+	if got := v.Complete("ca", 1); !slices.Equal(got, []string{"care"}) {
+		t.Errorf(`Complete("ca", 1) = %q, want [care]`, got)
+	}
+	if got := before.Suggest("ca", 1); !slices.Equal(got, []string{"cat"}) {
+		t.Errorf(`old snapshot Suggest("ca", 1) = %q, want [cat] (snapshots are frozen)`, got)
+	}
+}
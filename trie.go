@@ -1,13 +1,24 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unsafe"
+)
 
-const alphabetSize = 26 // For lowercase English letters 'a' through 'z'
+const alphabetSize = 28 // Apostrophe, hyphen, then lowercase English letters 'a' through 'z'
 
 // Node represents a node in the Trie structure.
 type Node struct {
 	children    [alphabetSize]*Node // Changed from map[byte]*Node to fixed-size array
 	isEndOfWord bool                // True if this node marks the end of a word
+	count       int                 // How many times the word ending here was inserted
+	wordCount   int                 // Number of distinct words ending at or below this node
 }
 
 // NewNode creates and returns a new Trie Node.
@@ -17,38 +28,331 @@ func NewNode() *Node {
 
 // Trie represents the Trie data structure.
 type Trie struct {
-	root *Node // The root node of the Trie
+	root        *Node       // The root node of the Trie
+	inputPolicy InputPolicy // What to do with characters outside the alphabet
+	foldCase    bool        // Lowercase ASCII letters before validating input
+
+	// Lifetime counters reported by Stats. They are atomic because read-only
+	// methods such as Search may run concurrently under a caller's read lock.
+	inserts, deletes, searches atomic.Uint64
 }
 
-// NewTrie creates and returns a new Trie.
-func NewTrie() *Trie {
-	return &Trie{
+// NewTrie creates and returns a new Trie configured by opts.
+// Without options, invalid input panics (see WithStrictInput).
+func NewTrie(opts ...Option) *Trie {
+	t := &Trie{
 		root: NewNode(),
 	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// NewTrieCaseInsensitive creates a Trie that treats "Cat", "CAT" and "cat" as
+// the same word; it is NewTrie(WithCaseInsensitive()) plus any further opts.
+func NewTrieCaseInsensitive(opts ...Option) *Trie {
+	return NewTrie(append([]Option{WithCaseInsensitive()}, opts...)...)
+}
+
+// InputPolicy decides how a Trie treats characters outside its alphabet in
+// every method that takes a word or prefix, and in BuildFromSorted and
+// NewTrieFromWords. Methods documented to accept arbitrary text (patterns,
+// fuzzy queries, LongestPrefixOf, InsertText) never panic on such characters
+// and are unaffected. ValueTrie and the other Trie variants have no policy.
+type InputPolicy int
+
+const (
+	// PanicOnInvalid panics with an InvalidCharError. It is the default and
+	// suits trusted LeetCode-style input.
+	PanicOnInvalid InputPolicy = iota
+	// SkipInvalid silently drops invalid characters, so "Don't!" is handled as "on't".
+	SkipInvalid
+	// RejectInvalid refuses the whole string: Insert stores nothing, lookups
+	// report false/empty, and the E-suffixed methods return the error.
+	RejectInvalid
+)
+
+// ErrInvalidChar is matched (via errors.Is) by every error reporting a
+// character outside the Trie alphabet.
+var ErrInvalidChar = errors.New("trie: character not in alphabet")
+
+// InvalidCharError reports the first character outside the Trie alphabet in
+// an input string and its byte position. It is what the E-suffixed methods
+// return, and what the plain methods panic with under PanicOnInvalid.
+// errors.Is(err, ErrInvalidChar) holds for it.
+type InvalidCharError struct {
+	Char byte
+	Pos  int
+}
+
+func (e InvalidCharError) Error() string {
+	return fmt.Sprintf("%v: %q at position %d", ErrInvalidChar, e.Char, e.Pos)
+}
+
+// Is makes InvalidCharError match ErrInvalidChar.
+func (e InvalidCharError) Is(target error) bool {
+	return target == ErrInvalidChar
+}
+
+// Option configures a Trie created by NewTrie.
+type Option func(*Trie)
+
+// WithStrictInput selects between panicking on invalid characters (true, the
+// default) and silently skipping them (false).
+func WithStrictInput(strict bool) Option {
+	return func(t *Trie) {
+		if strict {
+			t.inputPolicy = PanicOnInvalid
+		} else {
+			t.inputPolicy = SkipInvalid
+		}
+	}
+}
+
+// WithInputPolicy sets the invalid-input policy explicitly, e.g. RejectInvalid
+// for callers that want errors from the E-suffixed methods.
+func WithInputPolicy(p InputPolicy) Option {
+	return func(t *Trie) {
+		t.inputPolicy = p
+	}
+}
+
+// WithCaseInsensitive lowercases ASCII letters in every word, prefix, pattern
+// and query given to the Trie's methods, so stored words and results are
+// always lowercase. Folding happens before the input policy sees the string.
+func WithCaseInsensitive() Option {
+	return func(t *Trie) {
+		t.foldCase = true
+	}
 }
 
-// charToIndex converts a lowercase English letter byte to its corresponding array index (0-25).
-// It panics if the character is not a lowercase English letter.
+// accept applies the input policy for the plain (non-E) methods, which thus
+// share their validation with the E-suffixed ones. Under PanicOnInvalid it
+// panics with the InvalidCharError before anything is modified. ok is false
+// when RejectInvalid refuses s.
+func (t *Trie) accept(s string) (string, bool) {
+	s, err := t.input(s)
+	if err != nil && t.inputPolicy == PanicOnInvalid {
+		panic(err)
+	}
+	return s, err == nil
+}
+
+// input folds case if configured and then applies the input policy, returning
+// an InvalidCharError for invalid input unless the policy is SkipInvalid.
+// Valid lowercase input is returned unchanged without allocating. Together
+// with fold it is the single place where input is normalized, so every query
+// path agrees.
+func (t *Trie) input(s string) (string, error) {
+	s = t.fold(s)
+	if err := checkAlphabet(s); err != nil {
+		if t.inputPolicy == SkipInvalid {
+			return stripInvalid(s), nil
+		}
+		return "", err
+	}
+	return s, nil
+}
+
+// checkAlphabet returns an InvalidCharError for the first character of s
+// outside the alphabet, or nil if there is none.
+func checkAlphabet(s string) error {
+	for i := 0; i < len(s); i++ {
+		if _, ok := tryCharToIndex(s[i]); !ok {
+			return InvalidCharError{Char: s[i], Pos: i}
+		}
+	}
+	return nil
+}
+
+// fold lowercases s if the Trie is case-insensitive. Methods that tolerate
+// characters outside the alphabet (patterns, fuzzy queries, free text) call it
+// directly instead of input.
+func (t *Trie) fold(s string) string {
+	if t.foldCase {
+		return lowerASCII(s)
+	}
+	return s
+}
+
+// mustBeInAlphabet panics with an InvalidCharError if s contains a character
+// outside the alphabet.
+func mustBeInAlphabet(s string) {
+	if err := checkAlphabet(s); err != nil {
+		panic(err)
+	}
+}
+
+// lowerASCII returns s with 'A'-'Z' mapped to 'a'-'z'. It only allocates
+// when s actually contains an uppercase letter.
+func lowerASCII(s string) string {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= 'A' && c <= 'Z' {
+			b := []byte(s)
+			for j := i; j < len(b); j++ {
+				if b[j] >= 'A' && b[j] <= 'Z' {
+					b[j] += 'a' - 'A'
+				}
+			}
+			return string(b)
+		}
+	}
+	return s
+}
+
+// stripInvalid returns s without the bytes that are not in the alphabet.
+func stripInvalid(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if _, ok := tryCharToIndex(s[i]); ok {
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// InsertE is Insert that reports invalid input as an error instead of
+// panicking, whatever the policy (SkipInvalid still just drops the characters).
+func (t *Trie) InsertE(word string) error {
+	word, err := t.input(word)
+	if err != nil {
+		return err
+	}
+	t.Insert(word)
+	return nil
+}
+
+// SearchE is Search that reports invalid input as an error instead of panicking.
+func (t *Trie) SearchE(word string) (bool, error) {
+	word, err := t.input(word)
+	if err != nil {
+		return false, err
+	}
+	return t.Search(word), nil
+}
+
+// StartsWithE is StartsWith that reports invalid input as an error instead of panicking.
+func (t *Trie) StartsWithE(prefix string) (bool, error) {
+	prefix, err := t.input(prefix)
+	if err != nil {
+		return false, err
+	}
+	return t.StartsWith(prefix), nil
+}
+
+// DeleteE is Delete that reports invalid input as an error instead of panicking.
+func (t *Trie) DeleteE(word string) (bool, error) {
+	word, err := t.input(word)
+	if err != nil {
+		return false, err
+	}
+	return t.Delete(word), nil
+}
+
+// charToIndex converts an alphabet byte to its corresponding array index (0-27).
+// The apostrophe and hyphen come first so that index order matches byte order,
+// which keeps DFS output lexicographically sorted ("don't" < "dona").
+// It panics if the character is not a lowercase English letter, apostrophe or hyphen.
 func charToIndex(char byte) int {
-	if char >= 'a' && char <= 'z' {
-		return int(char - 'a')
+	if idx, ok := tryCharToIndex(char); ok {
+		return idx
 	}
 	// For production code, you might want to return an error or a special value
 	// instead of panicking, or handle non-lowercase inputs upstream.
-	panic("trie: character not a lowercase English letter")
+	panic("trie: character not a lowercase English letter, apostrophe or hyphen")
+}
+
+// tryCharToIndex is the non-panicking form of charToIndex; ok is false for
+// characters outside the alphabet.
+func tryCharToIndex(char byte) (int, bool) {
+	switch {
+	case char == '\'':
+		return 0, true
+	case char == '-':
+		return 1, true
+	case char >= 'a' && char <= 'z':
+		return int(char-'a') + 2, true
+	}
+	return 0, false
 }
 
 func indexToChar(i int) byte {
-	if i >= 0 && i < alphabetSize {
-		return byte('a' + i)
+	switch {
+	case i == 0:
+		return '\''
+	case i == 1:
+		return '-'
+	case i >= 2 && i < alphabetSize:
+		return byte('a' + i - 2)
 	}
 
 	panic("trie: char index out of range")
 }
 
-// Insert adds a word to the Trie.
+// find returns the node reached by walking s from the root, or nil if the
+// path doesn't exist. s must already have been through input.
+func (t *Trie) find(s string) *Node {
+	currentNode := t.root
+	for i := 0; i < len(s); i++ {
+		currentNode = currentNode.children[charToIndex(s[i])]
+		if currentNode == nil {
+			return nil
+		}
+	}
+	return currentNode
+}
+
+// Insert adds a word to the Trie. Inserting the same word again bumps its Count.
 // Assumes input 'word' contains only lowercase English letters.
 func (t *Trie) Insert(word string) {
+	t.insert(word)
+}
+
+// insert implements Insert, reporting whether word was newly added rather
+// than already present (or rejected by the input policy).
+func (t *Trie) insert(word string) bool {
+	word, ok := t.accept(word)
+	if !ok {
+		return false // Rejected by the input policy
+	}
+	currentNode := t.insertPath(word)
+	added := !currentNode.isEndOfWord
+	if added {
+		t.addWordCount(word, 1)
+	}
+	currentNode.isEndOfWord = true
+	currentNode.count++
+	t.inserts.Add(1)
+	return added
+}
+
+// InsertAll inserts every word, exactly as calling Insert on each would, and
+// returns how many of them were new. A word repeated within words, or already
+// stored, is counted at most once (its Count still goes up every time).
+func (t *Trie) InsertAll(words []string) int {
+	added := 0
+	for _, word := range words {
+		if t.insert(word) {
+			added++
+		}
+	}
+	return added
+}
+
+// addWordCount adds delta to the wordCount of every node on word's path, root included.
+// The path must already exist.
+func (t *Trie) addWordCount(word string, delta int) {
+	currentNode := t.root
+	currentNode.wordCount += delta
+	for i := 0; i < len(word); i++ {
+		currentNode = currentNode.children[charToIndex(word[i])]
+		currentNode.wordCount += delta
+	}
+}
+
+// insertPath walks word from the root, creating any missing nodes, and returns the last node.
+func (t *Trie) insertPath(word string) *Node {
 	currentNode := t.root
 	for i := 0; i < len(word); i++ {
 		idx := charToIndex(word[i])
@@ -57,12 +361,129 @@ func (t *Trie) Insert(word string) {
 		}
 		currentNode = currentNode.children[idx]
 	}
-	currentNode.isEndOfWord = true
+	return currentNode
+}
+
+// InsertText splits free text into words and inserts each one, so repeated
+// words accumulate counts. Tokens are separated by whitespace and punctuation
+// (apostrophes and hyphens excepted, so "don't" and "mother-in-law" stay one
+// token), then lowercased with any characters outside the alphabet stripped.
+// Empty tokens are skipped.
+func (t *Trie) InsertText(text string) {
+	tokens := strings.FieldsFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || (unicode.IsPunct(r) && r != '\'' && r != '-')
+	})
+	for _, token := range tokens {
+		if word := lowerAndKeep(token, isAlphabetChar); word != "" {
+			t.Insert(word)
+		}
+	}
+}
+
+// InsertNGrams inserts every length-n substring of every token in text.
+// Tokens are split on whitespace, lowercased, and stripped of anything that is
+// not a letter; tokens shorter than n contribute nothing.
+func (t *Trie) InsertNGrams(text string, n int) {
+	if n <= 0 {
+		return
+	}
+	for _, token := range strings.Fields(text) {
+		letters := lowerAndKeep(token, isLetter)
+		for i := 0; i+n <= len(letters); i++ {
+			t.Insert(letters[i : i+n])
+		}
+	}
+}
+
+// isLetter reports whether c is a lowercase English letter.
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+// isAlphabetChar reports whether c can be stored in the Trie.
+func isAlphabetChar(c byte) bool {
+	return isLetter(c) || c == '\'' || c == '-'
+}
+
+// lowerAndKeep lowercases s and drops every byte for which keep returns false.
+func lowerAndKeep(s string, keep func(c byte) bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		if keep(c) {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// BuildFromSorted creates a Trie from a lexicographically sorted slice of words.
+// Instead of descending from the root for every word, it keeps the path of the
+// previously inserted word and only walks/creates nodes for the suffix that
+// diverges from it. Unsorted input still produces a correct Trie, just without
+// the speedup. opts configure the Trie as in NewTrie, and its input policy
+// applies to every word: by default an invalid word panics before the Trie
+// is returned, under RejectInvalid it is left out.
+func BuildFromSorted(words []string, opts ...Option) *Trie {
+	t := NewTrie(opts...)
+	path := []*Node{t.root} // path[i] is the node reached after i characters of prev
+	prev := ""
+
+	for _, word := range words {
+		word, ok := t.accept(word)
+		if !ok {
+			continue // Rejected by the input policy
+		}
+		// Length of the prefix shared with the previous word
+		common := 0
+		for common < len(word) && common < len(prev) && word[common] == prev[common] {
+			common++
+		}
+
+		path = path[:common+1]
+		currentNode := path[common]
+		for i := common; i < len(word); i++ {
+			idx := charToIndex(word[i])
+			if currentNode.children[idx] == nil {
+				currentNode.children[idx] = NewNode()
+			}
+			currentNode = currentNode.children[idx]
+			path = append(path, currentNode)
+		}
+		if !currentNode.isEndOfWord {
+			for _, node := range path {
+				node.wordCount++
+			}
+		}
+		currentNode.isEndOfWord = true
+		currentNode.count++
+		prev = word
+	}
+
+	return t
+}
+
+// NewTrieFromWords creates a Trie containing words, in any order, configured
+// by opts. Together with Words it gives a trivial round-trip:
+// NewTrieFromWords(t.Words()).Equal(t).
+func NewTrieFromWords(words []string, opts ...Option) *Trie {
+	return BuildFromSorted(words, opts...) // Correct for unsorted input too, just slower
 }
 
 // Search checks if a word exists in the Trie.
+// It never allocates (short of case-folding uppercase input), so it is safe
+// to call on hot paths; keep it that way
+// (e.g. no fmt.Errorf or string building on the lookup path).
 // Assumes input 'word' contains only lowercase English letters.
 func (t *Trie) Search(word string) bool {
+	t.searches.Add(1)
+	word, ok := t.accept(word)
+	if !ok {
+		return false
+	}
 	currentNode := t.root
 	for i := 0; i < len(word); i++ {
 		idx := charToIndex(word[i])
@@ -74,9 +495,123 @@ func (t *Trie) Search(word string) bool {
 	return currentNode.isEndOfWord // True if it's a complete word, false otherwise (e.g., prefix)
 }
 
+// SearchDepth reports how many leading characters of word exist as a path in
+// the Trie (len(word) if the whole path exists) and whether word is a stored word.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *Trie) SearchDepth(word string) (depth int, found bool) {
+	word, ok := t.accept(word)
+	if !ok {
+		return 0, false
+	}
+	currentNode := t.root
+	for i := 0; i < len(word); i++ {
+		idx := charToIndex(word[i])
+		if currentNode.children[idx] == nil {
+			return i, false // Diverged after i matching characters
+		}
+		currentNode = currentNode.children[idx]
+	}
+	return len(word), currentNode.isEndOfWord
+}
+
+// SearchAll runs Search for every word and returns the results keyed by word
+// as given. A word containing characters outside the alphabet maps to false
+// instead of panicking, whatever the input policy (SkipInvalid still searches
+// for the word without them).
+func (t *Trie) SearchAll(words []string) map[string]bool {
+	results := make(map[string]bool, len(words))
+	for _, word := range words {
+		results[word] = t.searchSafe(word)
+	}
+	return results
+}
+
+// searchSafe is Search that reports false for invalid characters rather than panicking.
+func (t *Trie) searchSafe(word string) bool {
+	word, err := t.input(word)
+	if err != nil {
+		return false
+	}
+	node := t.find(word)
+	return node != nil && node.isEndOfWord
+}
+
+// PrefixCount returns how many distinct stored words start with prefix,
+// without collecting them: it reads the per-node word count kept up to date
+// by Insert, Delete and HardDelete, so it is O(len(prefix)).
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *Trie) PrefixCount(prefix string) int {
+	prefix, ok := t.accept(prefix)
+	if !ok {
+		return 0
+	}
+	currentNode := t.root
+	for i := 0; i < len(prefix); i++ {
+		currentNode = currentNode.children[charToIndex(prefix[i])]
+		if currentNode == nil {
+			return 0
+		}
+	}
+	return currentNode.wordCount
+}
+
+// AnyStartsWith reports whether at least one of prefixes has a stored word
+// starting with it, stopping at the first that does. A prefix containing
+// characters outside the alphabet never matches instead of panicking.
+func (t *Trie) AnyStartsWith(prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if t.startsWithSafe(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllStartWith reports whether every one of prefixes has a stored word
+// starting with it, stopping at the first that doesn't; it is true for an
+// empty list. As in AnyStartsWith, an invalid prefix simply doesn't match.
+func (t *Trie) AllStartWith(prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if !t.startsWithSafe(prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// startsWithSafe is StartsWith that reports false for invalid characters rather than panicking.
+func (t *Trie) startsWithSafe(prefix string) bool {
+	prefix, err := t.input(prefix)
+	if err != nil {
+		return false
+	}
+	node := t.find(prefix)
+	return node != nil && node.wordCount > 0
+}
+
+// Count returns how many times word has been inserted, or 0 if it is not stored.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *Trie) Count(word string) int {
+	word, ok := t.accept(word)
+	if !ok {
+		return 0
+	}
+	if node := t.find(word); node != nil {
+		return node.count
+	}
+	return 0
+}
+
 // StartsWith checks if there is any word in the Trie that starts with the given prefix.
+// Paths left behind by soft deletes don't count, so it answers the same
+// whether words were removed with Delete or HardDelete; on an empty Trie even
+// the empty prefix reports false. Like Search, it never allocates.
 // Assumes input 'prefix' contains only lowercase English letters.
 func (t *Trie) StartsWith(prefix string) bool {
+	prefix, ok := t.accept(prefix)
+	if !ok {
+		return false
+	}
 	currentNode := t.root
 	for i := 0; i < len(prefix); i++ {
 		idx := charToIndex(prefix[i])
@@ -85,13 +620,72 @@ func (t *Trie) StartsWith(prefix string) bool {
 		}
 		currentNode = currentNode.children[idx]
 	}
-	return true // Prefix found
+	return currentNode.wordCount > 0 // Prefix found, unless only soft-deleted words were below it
+}
+
+// LookupResult describes how a string relates to the words stored in a Trie.
+type LookupResult int
+
+const (
+	NotFound      LookupResult = iota // Neither a stored word nor a prefix of one
+	Prefix                            // A proper prefix of a stored word, but not a word itself
+	Word                              // A stored word that no other word extends
+	WordAndPrefix                     // A stored word that is also a proper prefix of another word
+)
+
+func (r LookupResult) String() string {
+	switch r {
+	case NotFound:
+		return "NotFound"
+	case Prefix:
+		return "Prefix"
+	case Word:
+		return "Word"
+	case WordAndPrefix:
+		return "WordAndPrefix"
+	}
+	return fmt.Sprintf("LookupResult(%d)", int(r))
+}
+
+// Lookup answers Search and "is s a proper prefix?" in a single descent.
+// Like StartsWith, it ignores paths that only lead to soft-deleted words.
+// Assumes input 's' contains only lowercase English letters.
+func (t *Trie) Lookup(s string) LookupResult {
+	s, ok := t.accept(s)
+	if !ok {
+		return NotFound
+	}
+	currentNode := t.find(s)
+	if currentNode == nil {
+		return NotFound
+	}
+
+	// Longer words below the node, i.e. stored words that s is a proper prefix of
+	longer := currentNode.wordCount
+	if currentNode.isEndOfWord {
+		longer--
+	}
+
+	switch {
+	case currentNode.isEndOfWord && longer > 0:
+		return WordAndPrefix
+	case currentNode.isEndOfWord:
+		return Word
+	case longer > 0:
+		return Prefix
+	}
+	return NotFound
 }
 
 // Delete removes a word from the Trie.
-// This implementation performs a "soft" delete by just unmarking isEndOfWord.
+// This implementation performs a "soft" delete by just unmarking isEndOfWord;
+// HardDelete also reclaims the nodes that no longer lead to any word.
 // Assumes input 'word' contains only lowercase English letters.
 func (t *Trie) Delete(word string) bool {
+	word, ok := t.accept(word)
+	if !ok {
+		return false
+	}
 	currentNode := t.root
 	// We need to keep track of the path for potential hard deletion later,
 	// but for soft delete, just direct traversal is enough.
@@ -109,20 +703,277 @@ func (t *Trie) Delete(word string) bool {
 	}
 
 	currentNode.isEndOfWord = false // Unmark as end of word
+	currentNode.count = 0
+	t.addWordCount(word, -1)
+	t.deletes.Add(1)
+
+	return true
+}
+
+// HardDelete removes a word and prunes the nodes left without a word at or
+// below them, so churning through words doesn't leave dead branches behind.
+// Pruning stops at the first node that still ends a word or leads to one:
+// deleting "app" leaves "apple" intact, and deleting "apple" keeps "app".
+// found reports whether word was stored, pruned whether any nodes were
+// unlinked. Dead branches left by earlier soft deletes are pruned along with
+// the path when they hang below an unlinked node.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *Trie) HardDelete(word string) (found, pruned bool) {
+	word, ok := t.accept(word)
+	if !ok {
+		return false, false
+	}
+	currentNode := t.root
+	for i := 0; i < len(word); i++ {
+		currentNode = currentNode.children[charToIndex(word[i])]
+		if currentNode == nil {
+			return false, false // Word not found
+		}
+	}
+	if !currentNode.isEndOfWord {
+		return false, false // Word exists as a prefix but not as a complete word
+	}
+
+	currentNode.isEndOfWord = false
+	currentNode.count = 0
+	t.addWordCount(word, -1)
+	t.deletes.Add(1)
+	return true, t.pruneDead(word)
+}
+
+// pruneDead unlinks the topmost node on path that no longer leads to any word,
+// reporting whether there was one. Thanks to wordCount there is no need to
+// walk back up: going down from the root, the first such node is the top of
+// the dead branch, and unlinking it frees everything beneath.
+// The path must exist.
+func (t *Trie) pruneDead(path string) bool {
+	parent := t.root
+	for i := 0; i < len(path); i++ {
+		idx := charToIndex(path[i])
+		child := parent.children[idx]
+		if child.wordCount == 0 {
+			parent.children[idx] = nil
+			return true
+		}
+		parent = child
+	}
+	return false // Every node on the path still leads to a word
+}
+
+// DeletePrefix removes every word starting with prefix by detaching the
+// subtree below it, and returns how many distinct words that removed. If the
+// ancestors of the subtree are left without words, they are unlinked too, as
+// in HardDelete. When no stored word starts with prefix, including when the
+// path only holds soft-deleted words, it returns 0 without modifying
+// anything; the empty prefix empties the Trie.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *Trie) DeletePrefix(prefix string) int {
+	prefix, ok := t.accept(prefix)
+	if !ok {
+		return 0
+	}
+	currentNode := t.root
+	for i := 0; i < len(prefix); i++ {
+		currentNode = currentNode.children[charToIndex(prefix[i])]
+		if currentNode == nil {
+			return 0
+		}
+	}
+
+	removed := currentNode.wordCount
+	if removed == 0 {
+		return 0 // Only soft-deleted words below; leave their nodes to Compact
+	}
+	if prefix == "" {
+		t.root = NewNode()
+	} else {
+		t.addWordCount(prefix, -removed)
+		t.pruneDead(prefix) // Unlinks the prefix node at the latest, now that its count is 0
+	}
+	t.deletes.Add(uint64(removed))
+	return removed
+}
+
+// CountWordsOfLength returns how many stored words are exactly n characters long.
+// For n == 0 that is 1 if the empty string is stored, otherwise 0.
+func (t *Trie) CountWordsOfLength(n int) int {
+	count := 0
+	t.lengthDFS(t.root, nil, n, func([]byte) { count++ })
+	return count
+}
+
+// WordsOfLength returns the stored words that are exactly n characters long, sorted.
+func (t *Trie) WordsOfLength(n int) []string {
+	words := []string{}
+	t.lengthDFS(t.root, nil, n, func(path []byte) { words = append(words, string(path)) })
+	return words
+}
+
+// lengthDFS calls visit with the path of every word of exactly n characters,
+// never descending deeper than n.
+func (t *Trie) lengthDFS(node *Node, path []byte, n int, visit func(path []byte)) {
+	if len(path) == n {
+		if node.isEndOfWord {
+			visit(path)
+		}
+		return // Anything deeper is too long
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil {
+			t.lengthDFS(child, append(path, indexToChar(i)), n, visit)
+		}
+	}
+}
+
+// SearchPattern reports whether any stored word matches pattern, where '.'
+// matches any single character (LeetCode 211). The pattern must match a whole
+// word: "ca." doesn't match "cart", and a pattern that only covers a prefix
+// of stored words doesn't match. It stops at the first match.
+func (t *Trie) SearchPattern(pattern string) bool {
+	return matchPattern(t.root, t.fold(pattern))
+}
+
+// matchPattern is a helper function for SearchPattern; pattern is what is
+// left to match below node.
+func matchPattern(node *Node, pattern string) bool {
+	if pattern == "" {
+		return node.isEndOfWord
+	}
+	if c := pattern[0]; c != '.' {
+		idx, ok := tryCharToIndex(c)
+		return ok && node.children[idx] != nil && matchPattern(node.children[idx], pattern[1:])
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil && matchPattern(child, pattern[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// CountPatternMatches returns how many stored words match pattern, where '.'
+// matches any single character, e.g. "c.t" matches "cat" and "cut" but not
+// "cart". Only the count is produced; no words are built. A character outside
+// the alphabet other than '.' simply matches nothing.
+func (t *Trie) CountPatternMatches(pattern string) int {
+	pattern = t.fold(pattern)
+	count := 0
+	t.patternDFS(t.root, pattern, make([]byte, 0, len(pattern)), func([]byte) { count++ })
+	return count
+}
 
-	// Hard delete logic (more complex):
-	// To perform a hard delete, you would need to iterate backwards from the
-	// currentNode through the path of nodes visited. For each node, check if:
-	// 1. It's no longer 'isEndOfWord'.
-	// 2. It has no other children (i.e., it's not a prefix to any other word).
-	// If both conditions are met, remove the node from its parent's children array.
+// patternDFS calls visit with the path of every stored word matching
+// pattern[len(path):] below node, branching over all children at each '.'.
+func (t *Trie) patternDFS(node *Node, pattern string, path []byte, visit func(path []byte)) {
+	if len(path) == len(pattern) {
+		if node.isEndOfWord {
+			visit(path)
+		}
+		return
+	}
+	if c := pattern[len(path)]; c != '.' {
+		if idx, ok := tryCharToIndex(c); ok && node.children[idx] != nil {
+			t.patternDFS(node.children[idx], pattern, append(path, c), visit)
+		}
+		return
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil {
+			t.patternDFS(child, pattern, append(path, indexToChar(i)), visit)
+		}
+	}
+}
 
+// Iterate calls fn for every stored word in sorted order, stopping as soon as
+// fn returns false; the DFS unwinds right away instead of walking the rest.
+// Only one word is materialized at a time. Its signature matches
+// iter.Seq[string], so `for word := range t.Iterate` works as well.
+func (t *Trie) Iterate(fn func(word string) bool) {
+	iterateDFS(t.root, nil, fn)
+}
+
+// iterateDFS is a helper function for Iterate. It returns false once fn has
+// asked to stop.
+func iterateDFS(node *Node, path []byte, fn func(word string) bool) bool {
+	if node.isEndOfWord && !fn(string(path)) {
+		return false
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil {
+			if !iterateDFS(child, append(path, indexToChar(i)), fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Words returns every stored word in sorted order.
+func (t *Trie) Words() []string {
+	return t.CollectAllWordsStartingWith("")
+}
+
+// Equal reports whether t and other store exactly the same set of words.
+// Insertion counts and nodes left behind by soft deletes are ignored.
+func (t *Trie) Equal(other *Trie) bool {
+	return equalNodes(t.root, other.root)
+}
+
+// equalNodes reports whether the word sets below a and b are the same.
+func equalNodes(a, b *Node) bool {
+	if a.isEndOfWord != b.isEndOfWord {
+		return false
+	}
+	for i := 0; i < alphabetSize; i++ {
+		childA, childB := a.children[i], b.children[i]
+		emptyA := childA == nil || childA.wordCount == 0
+		emptyB := childB == nil || childB.wordCount == 0
+		if emptyA != emptyB {
+			return false
+		}
+		if !emptyA && !equalNodes(childA, childB) {
+			return false
+		}
+	}
 	return true
 }
 
+// Compact removes every node that no longer leads to a word, which is the
+// cleanup soft Delete skips, and returns how many nodes were reclaimed.
+// Remaining words, counts and prefixes are unaffected.
+func (t *Trie) Compact() int {
+	return compactNode(t.root)
+}
+
+// compactNode detaches node's children that have no words below them and
+// recurses into the rest, returning the number of nodes removed.
+func compactNode(node *Node) int {
+	removed := 0
+	for i := 0; i < alphabetSize; i++ {
+		child := node.children[i]
+		if child == nil {
+			continue
+		}
+		if child.wordCount == 0 {
+			removed += countNodes(child)
+			node.children[i] = nil
+		} else {
+			removed += compactNode(child)
+		}
+	}
+	return removed
+}
+
 // CollectAllWordsStartingWith collects all words in the Trie that start with the given prefix.
+// The result is guaranteed to be in strictly increasing lexicographic (byte) order:
+// the DFS visits children in index order, and charToIndex assigns indices in byte
+// order. Any change to the node layout must preserve this contract.
 // Assumes input 'prefix' contains only lowercase English letters.
 func (t *Trie) CollectAllWordsStartingWith(prefix string) []string {
+	prefix, ok := t.accept(prefix)
+	if !ok {
+		return []string{}
+	}
 	var words []string
 	currentNode := t.root
 
@@ -147,7 +998,7 @@ func (t *Trie) collectWordsDFS(node *Node, currentWord string, words *[]string)
 		*words = append(*words, currentWord)
 	}
 
-	// Iterate over the fixed-size array
+	// Iterate over the fixed-size array in index order, which keeps the output sorted
 	for i := 0; i < alphabetSize; i++ {
 		childNode := node.children[i]
 		if childNode != nil {
@@ -158,9 +1009,705 @@ func (t *Trie) collectWordsDFS(node *Node, currentWord string, words *[]string)
 	}
 }
 
-// Example Usage (main function to test):
+// Suggest returns up to limit words starting with prefix, most frequently
+// inserted first (see Count); equally frequent words are ordered
+// alphabetically. A limit of 0 or less returns an empty slice.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *Trie) Suggest(prefix string, limit int) []string {
+	prefix, ok := t.accept(prefix)
+	if !ok || limit <= 0 {
+		return []string{}
+	}
+	currentNode := t.root
+	for i := 0; i < len(prefix); i++ {
+		currentNode = currentNode.children[charToIndex(prefix[i])]
+		if currentNode == nil {
+			return []string{}
+		}
+	}
+
+	var s Snapshot
+	t.snapshotDFS(currentNode, prefix, &s)
+	order := make([]int, len(s.words))
+	for i := range order {
+		order[i] = i
+	}
+	// The DFS already yields words sorted, so a stable sort by count keeps ties alphabetical.
+	sort.SliceStable(order, func(i, j int) bool {
+		return s.counts[order[i]] > s.counts[order[j]]
+	})
+
+	words := make([]string, 0, min(limit, len(order)))
+	for _, i := range order[:min(limit, len(order))] {
+		words = append(words, s.words[i])
+	}
+	return words
+}
+
+// LongestCommonPrefix returns the longest prefix shared by every stored word:
+// "fl" for flower, flow and flight. It walks down from the root while the
+// current node has exactly one child leading to a word and doesn't end a word
+// itself. It is "" for an empty Trie and the word itself for a single word.
+// Branches left behind by soft deletes are ignored.
+func (t *Trie) LongestCommonPrefix() string {
+	var prefix []byte
+	currentNode := t.root
+	for !currentNode.isEndOfWord {
+		next := -1
+		for i := 0; i < alphabetSize; i++ {
+			if child := currentNode.children[i]; child != nil && child.wordCount > 0 {
+				if next != -1 {
+					return string(prefix) // Branches here
+				}
+				next = i
+			}
+		}
+		if next == -1 {
+			break // Empty Trie
+		}
+		prefix = append(prefix, indexToChar(next))
+		currentNode = currentNode.children[next]
+	}
+	return string(prefix)
+}
+
+// LongestPrefixOf returns the longest stored word that is a prefix of text,
+// e.g. "app" for "apple" given a, ap and app. text itself counts if stored.
+// ok is false when no stored word is a prefix of text. Scanning stops at the
+// first character outside the alphabet, so text may be arbitrary.
+func (t *Trie) LongestPrefixOf(text string) (string, bool) {
+	text = t.fold(text)
+	longest, found := 0, t.root.isEndOfWord
+	currentNode := t.root
+	for i := 0; i < len(text); i++ {
+		idx, ok := tryCharToIndex(text[i])
+		if !ok || currentNode.children[idx] == nil {
+			break
+		}
+		currentNode = currentNode.children[idx]
+		if currentNode.isEndOfWord {
+			longest, found = i+1, true
+		}
+	}
+	return text[:longest], found
+}
+
+// Snapshot is an opaque copy of a Trie's contents, taken by Checkpoint.
+type Snapshot struct {
+	words  []string // Sorted, as produced by the DFS
+	counts []int    // counts[i] is the insertion count of words[i]
+}
+
+// Checkpoint captures the current words and their counts so the Trie can be
+// rolled back with Restore. Later changes to the Trie don't affect the Snapshot.
+func (t *Trie) Checkpoint() Snapshot {
+	var s Snapshot
+	t.snapshotDFS(t.root, "", &s)
+	return s
+}
+
+// snapshotDFS is a helper function for Checkpoint that records every word and its count.
+func (t *Trie) snapshotDFS(node *Node, currentWord string, s *Snapshot) {
+	if node.isEndOfWord {
+		s.words = append(s.words, currentWord)
+		s.counts = append(s.counts, node.count)
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if node.children[i] != nil {
+			t.snapshotDFS(node.children[i], currentWord+string(indexToChar(i)), s)
+		}
+	}
+}
+
+// Restore reverts the Trie to the state captured by s, discarding everything
+// inserted or deleted since. The Trie is rebuilt from scratch, so nodes left
+// behind by soft deletes are reclaimed as well.
+func (t *Trie) Restore(s Snapshot) {
+	t.root = NewNode()
+	for i, word := range s.words {
+		node := t.insertPath(word)
+		node.isEndOfWord = true
+		node.count = s.counts[i]
+		t.addWordCount(word, 1)
+	}
+}
+
+// SearchWithinDamerauDistance returns every stored word within maxDist of word
+// under the (optimal string alignment) Damerau-Levenshtein distance, where an
+// insertion, deletion, substitution or swap of two adjacent characters each
+// cost 1. So "teh" matches "the" at maxDist 1. Results are sorted; as in
+// SearchFuzzy, a stored empty word is included when len(word) <= maxDist,
+// and a negative maxDist matches nothing.
+//
+// The DP row for each node is computed from its parent's row (and grandparent's,
+// for transpositions) during the DFS, and subtrees whose row minimum already
+// exceeds maxDist are pruned.
+func (t *Trie) SearchWithinDamerauDistance(word string, maxDist int) []string {
+	results := []string{}
+	if maxDist < 0 {
+		return results
+	}
+	word = t.fold(word)
+	firstRow := make([]int, len(word)+1)
+	for j := range firstRow {
+		firstRow[j] = j
+	}
+	if t.root.isEndOfWord && firstRow[len(word)] <= maxDist {
+		results = append(results, "")
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if child := t.root.children[i]; child != nil {
+			t.damerauDFS(child, indexToChar(i), 0, string(indexToChar(i)), word, firstRow, nil, maxDist, &results)
+		}
+	}
+	return results
+}
+
+// damerauDFS is a helper function for SearchWithinDamerauDistance.
+// char is the edge leading to node, prevChar the edge before it (0 at depth 1),
+// and prevRow/prevPrevRow are the DP rows of the parent and grandparent.
+func (t *Trie) damerauDFS(node *Node, char, prevChar byte, currentWord, word string, prevRow, prevPrevRow []int, maxDist int, results *[]string) {
+	row := damerauRow(word, char, prevChar, prevRow, prevPrevRow)
+
+	if node.isEndOfWord && row[len(word)] <= maxDist {
+		*results = append(*results, currentWord)
+	}
+	if minOf(row) > maxDist {
+		return // Every extension of this path is too far away
+	}
 
-func main() {
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil {
+			c := indexToChar(i)
+			t.damerauDFS(child, c, char, currentWord+string(c), word, row, prevRow, maxDist, results)
+		}
+	}
+}
+
+// SearchFuzzy returns every stored word within Levenshtein distance maxDist
+// of word, sorted: insertions, deletions and substitutions cost 1 each, so
+// "caat" finds "cat" at maxDist 1. Unlike SearchWithinDamerauDistance a swap
+// of adjacent characters costs 2. With maxDist 0 the result is just word if
+// it is stored; a negative maxDist matches nothing.
+//
+// As in SearchWithinDamerauDistance, each node's DP row is derived from its
+// parent's during the DFS and hopeless subtrees are pruned.
+func (t *Trie) SearchFuzzy(word string, maxDist int) []string {
+	results := []string{}
+	if maxDist < 0 {
+		return results
+	}
+	word = t.fold(word)
+	firstRow := make([]int, len(word)+1)
+	for j := range firstRow {
+		firstRow[j] = j
+	}
+	if t.root.isEndOfWord && firstRow[len(word)] <= maxDist {
+		results = append(results, "")
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if child := t.root.children[i]; child != nil {
+			t.levenshteinDFS(child, indexToChar(i), string(indexToChar(i)), word, firstRow, maxDist, &results)
+		}
+	}
+	return results
+}
+
+// levenshteinDFS is a helper function for SearchFuzzy. char is the edge
+// leading to node and prevRow the parent's DP row.
+func (t *Trie) levenshteinDFS(node *Node, char byte, currentWord, word string, prevRow []int, maxDist int, results *[]string) {
+	row := damerauRow(word, char, 0, prevRow, nil) // No grandparent row: plain Levenshtein
+
+	if node.isEndOfWord && row[len(word)] <= maxDist {
+		*results = append(*results, currentWord)
+	}
+	if minOf(row) > maxDist {
+		return // Every extension of this path is too far away
+	}
+
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil {
+			c := indexToChar(i)
+			t.levenshteinDFS(child, c, currentWord+string(c), word, row, maxDist, results)
+		}
+	}
+}
+
+// fuzzyMatch is a completion found by FuzzyComplete.
+type fuzzyMatch struct {
+	word  string
+	dist  int // Distance between the typed prefix and the closest prefix of word
+	count int
+}
+
+// FuzzyComplete completes a possibly mistyped prefix: it finds every trie path
+// within maxDist (Damerau-Levenshtein) of prefix and collects the words below
+// it, so "aplication" still surfaces "application". Up to limit words are
+// returned, ranked by prefix distance, then by descending insertion count,
+// then lexicographically. A limit of 0 or less returns an empty slice.
+func (t *Trie) FuzzyComplete(prefix string, maxDist, limit int) []string {
+	if limit <= 0 {
+		return []string{}
+	}
+
+	prefix = t.fold(prefix)
+	firstRow := make([]int, len(prefix)+1)
+	for j := range firstRow {
+		firstRow[j] = j
+	}
+	var matches []fuzzyMatch
+	t.fuzzyCompleteDFS(t.root, 0, "", prefix, firstRow, nil, firstRow[len(prefix)], maxDist, &matches)
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.dist != b.dist {
+			return a.dist < b.dist
+		}
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		return a.word < b.word
+	})
+
+	words := make([]string, 0, min(limit, len(matches)))
+	for i := 0; i < len(matches) && i < limit; i++ {
+		words = append(words, matches[i].word)
+	}
+	return words
+}
+
+// fuzzyCompleteDFS is a helper function for FuzzyComplete. row is the DP row of
+// the path ending at node (prevRow its parent's), and best is the smallest
+// distance between prefix and any prefix of that path seen so far.
+func (t *Trie) fuzzyCompleteDFS(node *Node, char byte, currentWord, prefix string, row, prevRow []int, best, maxDist int, matches *[]fuzzyMatch) {
+	if node.isEndOfWord && best <= maxDist {
+		*matches = append(*matches, fuzzyMatch{word: currentWord, dist: best, count: node.count})
+	}
+	if best > maxDist && minOf(row) > maxDist {
+		return // No prefix of any word below can get within maxDist
+	}
+
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil {
+			c := indexToChar(i)
+			childRow := damerauRow(prefix, c, char, row, prevRow)
+			t.fuzzyCompleteDFS(child, c, currentWord+string(c), prefix, childRow, row, min(best, childRow[len(prefix)]), maxDist, matches)
+		}
+	}
+}
+
+// damerauRow computes the next Damerau-Levenshtein DP row for appending char to
+// the trie path, given the rows for the path without its last one and two characters.
+func damerauRow(word string, char, prevChar byte, prevRow, prevPrevRow []int) []int {
+	row := make([]int, len(word)+1)
+	row[0] = prevRow[0] + 1
+	for j := 1; j <= len(word); j++ {
+		cost := 1
+		if word[j-1] == char {
+			cost = 0
+		}
+		row[j] = min(prevRow[j]+1, row[j-1]+1, prevRow[j-1]+cost)
+		if prevPrevRow != nil && j > 1 && word[j-1] == prevChar && word[j-2] == char {
+			row[j] = min(row[j], prevPrevRow[j-2]+1) // Adjacent transposition
+		}
+	}
+	return row
+}
+
+// minOf returns the smallest value in a non-empty row.
+func minOf(row []int) int {
+	m := row[0]
+	for _, v := range row[1:] {
+		m = min(m, v)
+	}
+	return m
+}
+
+// Validate checks the Trie's structural invariants and returns a descriptive
+// error for the first violation found, or nil if the Trie is consistent:
+//   - every node is reachable from the root exactly once (no shared or cyclic children),
+//   - no non-root node is an orphan, i.e. has no children and doesn't end a word,
+//   - a node ends a word if and only if its insertion count is positive,
+//   - every node's wordCount equals the number of words ending at or below it.
+//
+// Note that soft Delete can leave orphan nodes behind, which Validate reports.
+func (t *Trie) Validate() error {
+	if t.root == nil {
+		return fmt.Errorf("trie: root is nil")
+	}
+	_, err := t.validateDFS(t.root, "", make(map[*Node]bool))
+	return err
+}
+
+// validateDFS is a helper function for Validate. It returns the number of
+// words ending at or below node so parents can check their wordCount.
+func (t *Trie) validateDFS(node *Node, path string, seen map[*Node]bool) (int, error) {
+	if seen[node] {
+		return 0, fmt.Errorf("trie: node at %q is reachable more than once", path)
+	}
+	seen[node] = true
+
+	if node.isEndOfWord != (node.count > 0) {
+		return 0, fmt.Errorf("trie: node at %q has isEndOfWord=%t but count=%d", path, node.isEndOfWord, node.count)
+	}
+
+	words := 0
+	if node.isEndOfWord {
+		words = 1
+	}
+	hasChildren := false
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil {
+			hasChildren = true
+			childWords, err := t.validateDFS(child, path+string(indexToChar(i)), seen)
+			if err != nil {
+				return 0, err
+			}
+			words += childWords
+		}
+	}
+	if node != t.root && !hasChildren && !node.isEndOfWord {
+		return 0, fmt.Errorf("trie: orphan node at %q has no children and ends no word", path)
+	}
+	if node.wordCount != words {
+		return 0, fmt.Errorf("trie: node at %q has wordCount=%d but %d words below it", path, node.wordCount, words)
+	}
+	return words, nil
+}
+
+// CollectWithPrefixPaged returns one page of the sorted completions of prefix:
+// up to limit words, starting after the first offset words. The DFS counts past
+// the skipped words without building them and stops as soon as the page is full.
+// An offset at or beyond the number of completions yields an empty slice.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *Trie) CollectWithPrefixPaged(prefix string, offset, limit int) []string {
+	page := []string{}
+	prefix, ok := t.accept(prefix)
+	if !ok || offset < 0 || limit <= 0 {
+		return page
+	}
+
+	currentNode := t.find(prefix)
+	if currentNode == nil {
+		return page
+	}
+
+	skip := offset
+	t.pagedDFS(currentNode, []byte(prefix), &skip, limit, &page)
+	return page
+}
+
+// pagedDFS is a helper function for CollectWithPrefixPaged. It returns false
+// once the page is full so the whole recursion unwinds.
+func (t *Trie) pagedDFS(node *Node, path []byte, skip *int, limit int, page *[]string) bool {
+	if node.isEndOfWord {
+		if *skip > 0 {
+			*skip--
+		} else {
+			*page = append(*page, string(path))
+			if len(*page) == limit {
+				return false
+			}
+		}
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil {
+			if !t.pagedDFS(child, append(path, indexToChar(i)), skip, limit, page) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// TotalPrefixes returns the number of distinct non-empty prefixes of the stored
+// words. Every such prefix is a node with at least one word below it, so this is
+// NodeCount() minus the root, not counting nodes left behind by soft deletes.
+func (t *Trie) TotalPrefixes() int {
+	total := 0
+	for i := 0; i < alphabetSize; i++ {
+		if child := t.root.children[i]; child != nil {
+			total += countPrefixNodes(child) // The root itself is the empty prefix
+		}
+	}
+	return total
+}
+
+// countPrefixNodes counts node and its descendants that have words below them.
+func countPrefixNodes(node *Node) int {
+	if node.wordCount == 0 {
+		return 0
+	}
+	count := 1
+	for i := 0; i < alphabetSize; i++ {
+		if node.children[i] != nil {
+			count += countPrefixNodes(node.children[i])
+		}
+	}
+	return count
+}
+
+// MinUniquePrefixes maps every stored word to its shortest prefix that no other
+// stored word shares, in a single traversal: the prefix ends at the shallowest
+// node whose subtree holds only that word. A word that is itself a prefix of
+// another word has no such prefix and maps to itself.
+func (t *Trie) MinUniquePrefixes() map[string]string {
+	prefixes := make(map[string]string)
+	t.minUniqueDFS(t.root, nil, -1, prefixes)
+	return prefixes
+}
+
+// ShortestUniquePrefixes is another name for MinUniquePrefixes, under which
+// the classic "shortest prefix identifying each word" problem is usually asked.
+func (t *Trie) ShortestUniquePrefixes() map[string]string {
+	return t.MinUniquePrefixes()
+}
+
+// minUniqueDFS is a helper function for MinUniquePrefixes. uniqueLen is the
+// length of the shortest path prefix whose node holds a single word, or -1.
+func (t *Trie) minUniqueDFS(node *Node, path []byte, uniqueLen int, prefixes map[string]string) {
+	if uniqueLen < 0 && len(path) > 0 && node.wordCount == 1 {
+		uniqueLen = len(path)
+	}
+	if node.isEndOfWord {
+		word := string(path)
+		if uniqueLen < 0 {
+			prefixes[word] = word
+		} else {
+			prefixes[word] = word[:uniqueLen]
+		}
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil && child.wordCount > 0 {
+			t.minUniqueDFS(child, append(path, indexToChar(i)), uniqueLen, prefixes)
+		}
+	}
+}
+
+// NextCharWeights maps each character that can follow prefix to the number of
+// stored words that continue with it, e.g. {'t': 2, 'r': 3} for "ca" when the
+// Trie holds two words under "cat" and three under "car". Each lookup is O(1)
+// per child thanks to the per-node word counts. A missing prefix yields an empty map.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *Trie) NextCharWeights(prefix string) map[byte]int {
+	weights := make(map[byte]int)
+	prefix, ok := t.accept(prefix)
+	if !ok {
+		return weights
+	}
+	currentNode := t.find(prefix)
+	if currentNode == nil {
+		return weights
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if child := currentNode.children[i]; child != nil && child.wordCount > 0 {
+			weights[indexToChar(i)] = child.wordCount
+		}
+	}
+	return weights
+}
+
+// UniquePrefixOf returns the shortest prefix of word that no other stored word
+// starts with. ok is false if word isn't stored, or if no such prefix exists
+// because word is itself a prefix of another stored word.
+// Assumes input 'word' contains only lowercase English letters.
+func (t *Trie) UniquePrefixOf(word string) (string, bool) {
+	word, ok := t.accept(word)
+	if !ok {
+		return "", false
+	}
+	if node := t.find(word); node == nil || !node.isEndOfWord {
+		return "", false
+	}
+	currentNode := t.root
+	for i := 0; i < len(word); i++ {
+		currentNode = currentNode.children[charToIndex(word[i])]
+		if currentNode.wordCount == 1 {
+			return word[:i+1], true // Only word lives below this node
+		}
+	}
+	return "", false
+}
+
+// GroupByPrefix buckets every stored word by its first k characters. Words
+// shorter than k are keyed by the whole word: with k=2, "a" is keyed "a" while
+// "ab" and "abc" share the "ab" bucket. Each bucket is sorted, and every word
+// appears in exactly one bucket.
+func (t *Trie) GroupByPrefix(k int) map[string][]string {
+	groups := make(map[string][]string)
+	for _, word := range t.CollectAllWordsStartingWith("") {
+		key := word[:min(max(k, 0), len(word))]
+		groups[key] = append(groups[key], word)
+	}
+	return groups
+}
+
+// CollectWithPrefixCtx is CollectAllWordsStartingWith for request-scoped use:
+// it stops after limit words (limit <= 0 means no cap) and checks ctx as it
+// walks. If ctx is cancelled before collection finishes it returns nil and
+// ctx.Err(), never a partial result.
+// Assumes input 'prefix' contains only lowercase English letters.
+func (t *Trie) CollectWithPrefixCtx(ctx context.Context, prefix string, limit int) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	prefix, ok := t.accept(prefix)
+	if !ok {
+		return []string{}, nil
+	}
+	currentNode := t.find(prefix)
+	if currentNode == nil {
+		return []string{}, nil
+	}
+
+	words := []string{}
+	if err := t.ctxDFS(ctx, currentNode, []byte(prefix), limit, &words); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
+// ctxDFS is a helper function for CollectWithPrefixCtx.
+func (t *Trie) ctxDFS(ctx context.Context, node *Node, path []byte, limit int, words *[]string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if node.isEndOfWord {
+		*words = append(*words, string(path))
+	}
+	for i := 0; i < alphabetSize; i++ {
+		if limit > 0 && len(*words) >= limit {
+			return nil // Cap reached; every caller up the stack stops here too
+		}
+		if child := node.children[i]; child != nil {
+			if err := t.ctxDFS(ctx, child, append(path, indexToChar(i)), limit, words); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CountWords returns the number of distinct words stored. Soft-deleted words
+// don't count. It reads the root's word count, so it is O(1).
+func (t *Trie) CountWords() int {
+	return t.root.wordCount
+}
+
+// NodeCount returns the total number of allocated nodes, including the root.
+// Nodes left behind by soft deletes are still allocated and so still counted;
+// HardDelete or Compact reclaims them.
+func (t *Trie) NodeCount() int {
+	return countNodes(t.root)
+}
+
+// countNodes counts node and every node below it.
+func countNodes(node *Node) int {
+	count := 1
+	for i := 0; i < alphabetSize; i++ {
+		if node.children[i] != nil {
+			count += countNodes(node.children[i])
+		}
+	}
+	return count
+}
+
+// LeafCount returns the number of nodes without children.
+func (t *Trie) LeafCount() int {
+	return t.shape().leaves
+}
+
+// AverageBranchingFactor returns the mean number of children over internal
+// (non-leaf) nodes, or 0 for a Trie with no words.
+func (t *Trie) AverageBranchingFactor() float64 {
+	return t.shape().branching()
+}
+
+// trieShape summarizes a Trie's structure, as gathered by shape.
+type trieShape struct {
+	leaves, internal int // Nodes without and with children
+	edges            int // Parent-child links
+	maxDepth         int // Length of the longest stored word
+}
+
+// branching returns the mean number of children per internal node, or 0 if there are none.
+func (s trieShape) branching() float64 {
+	if s.internal == 0 {
+		return 0
+	}
+	return float64(s.edges) / float64(s.internal)
+}
+
+// shape measures the whole Trie in one traversal.
+func (t *Trie) shape() trieShape {
+	var s trieShape
+	shapeOf(t.root, 0, &s)
+	return s
+}
+
+// shapeOf is the recursive helper for shape, adding node (at depth) and
+// everything below it to s.
+func shapeOf(node *Node, depth int, s *trieShape) {
+	if node.isEndOfWord {
+		s.maxDepth = max(s.maxDepth, depth)
+	}
+	children := 0
+	for i := 0; i < alphabetSize; i++ {
+		if child := node.children[i]; child != nil {
+			children++
+			shapeOf(child, depth+1, s)
+		}
+	}
+	if children == 0 {
+		s.leaves++
+		return
+	}
+	s.internal++
+	s.edges += children
+}
+
+// EstimatedBytes returns an approximate heap footprint of the Trie in bytes.
+// Every node is a fixed-size array of child pointers plus its flags, so the
+// estimate is NodeCount() * sizeof(Node) plus the Trie header itself.
+// It ignores allocator rounding, so treat it as a lower bound.
+func (t *Trie) EstimatedBytes() int {
+	return t.NodeCount()*int(unsafe.Sizeof(Node{})) + int(unsafe.Sizeof(Trie{}))
+}
+
+// TrieStats is a point-in-time summary of a Trie returned by Stats.
+type TrieStats struct {
+	Inserts  uint64 // Insert calls that stored a word, repeats included
+	Deletes  uint64 // Words removed by Delete, HardDelete or DeletePrefix
+	Searches uint64 // Search calls, whatever their outcome
+
+	Words        int     // Distinct words currently stored
+	Nodes        int     // Allocated nodes, root and soft-delete leftovers included
+	MaxDepth     int     // Length of the longest stored word
+	AvgBranching float64 // Children per internal node, as AverageBranchingFactor
+}
+
+// Stats returns the lifetime operation counters since construction together
+// with the current word count and shape, which takes a single traversal.
+// Deleting a missing word is not counted, nor is an insert or delete refused
+// by the input policy; *E calls that fail validation never reach the plain
+// method, so they don't count either. Bulk loaders that bypass Insert, such
+// as BuildFromSorted and Restore, leave the counters alone.
+func (t *Trie) Stats() TrieStats {
+	shape := t.shape()
+	return TrieStats{
+		Inserts:  t.inserts.Load(),
+		Deletes:  t.deletes.Load(),
+		Searches: t.searches.Load(),
+
+		Words:        t.CountWords(),
+		Nodes:        shape.leaves + shape.internal,
+		MaxDepth:     shape.maxDepth,
+		AvgBranching: shape.branching(),
+	}
+}
+
+// trieDemo walks through the Trie API; run it with "go run . trie".
+func trieDemo() {
 	trie := NewTrie()
 
 	trie.Insert("cat")
@@ -182,8 +1729,8 @@ func main() {
 	fmt.Println("Starts with 'app':", trie.StartsWith("app")) // true
 	fmt.Println("Starts with 'co':", trie.StartsWith("co"))   // false
 
-	fmt.Println("Words starting with 'a':", trie.CollectAllWordsStartingWith("a"))     // [apple app application]
-	fmt.Println("Words starting with 'app':", trie.CollectAllWordsStartingWith("app")) // [apple app application]
+	fmt.Println("Words starting with 'a':", trie.CollectAllWordsStartingWith("a"))     // [app apple application]
+	fmt.Println("Words starting with 'app':", trie.CollectAllWordsStartingWith("app")) // [app apple application]
 	fmt.Println("Words starting with 'z':", trie.CollectAllWordsStartingWith("z"))     // []
 
 	fmt.Println("Delete 'app':", trie.Delete("app"))                                    // true
@@ -193,6 +1740,22 @@ func main() {
 
 	fmt.Println("Delete 'nonexistent':", trie.Delete("nonexistent")) // false
 
+	fmt.Println("Lookup 'car':", trie.Lookup("car"), "Lookup 'ca':", trie.Lookup("ca")) // WordAndPrefix Prefix
+
+	sorted := BuildFromSorted([]string{"app", "apple", "application", "car", "card", "cat"})
+	fmt.Println("Built from sorted, words with 'ca':", sorted.CollectAllWordsStartingWith("ca")) // [car card cat]
+
+	text := NewTrie()
+	text.InsertText("The cat sat. The cat, it purred!")
+	fmt.Println("Count 'the':", text.Count("the"), "Count 'cat':", text.Count("cat")) // 2 2
+
+	trie.Insert("don't")
+	trie.Insert("mother-in-law")
+	fmt.Println("Search \"don't\":", trie.Search("don't"))                           // true
+	fmt.Println("Words starting with 'mo':", trie.CollectAllWordsStartingWith("mo")) // [mother-in-law]
+
+	fmt.Println("Node count:", trie.NodeCount(), "estimated bytes:", trie.EstimatedBytes())
+
 	// Demonstrating panic for invalid input (uncomment to test):
 	// trie.Insert("ApPle") // Panics because 'A' is not lowercase
 }
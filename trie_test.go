@@ -0,0 +1,2185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// newTrieWith returns a default Trie holding words.
+func newTrieWith(words ...string) *Trie {
+	t := NewTrie()
+	for _, word := range words {
+		t.Insert(word)
+	}
+	return t
+}
+
+var sampleWords = []string{"cat", "car", "card", "apple", "app", "application"}
+
+func TestTrieSearch(t *testing.T) {
+	trie := newTrieWith(sampleWords...)
+	tests := []struct {
+		name string
+		word string
+		want bool
+	}{
+		{"stored word", "cat", true},
+		{"word that is also a prefix", "app", true},
+		{"longest word", "application", true},
+		{"prefix only", "ca", false},
+		{"prefix of a longer word", "appl", false},
+		{"extends a stored word", "cats", false},
+		{"unknown first letter", "dog", false},
+		{"diverges midway", "cow", false},
+		{"empty string not stored", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trie.Search(tt.word); got != tt.want {
+				t.Errorf("Search(%q) = %t, want %t", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrieStartsWith(t *testing.T) {
+	trie := newTrieWith(sampleWords...)
+	tests := []struct {
+		name   string
+		prefix string
+		want   bool
+	}{
+		{"proper prefix", "ca", true},
+		{"whole word", "card", true},
+		{"word that is also a prefix", "app", true},
+		{"single letter", "a", true},
+		{"empty prefix", "", true},
+		{"missing", "co", false},
+		{"longer than any word", "cards", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trie.StartsWith(tt.prefix); got != tt.want {
+				t.Errorf("StartsWith(%q) = %t, want %t", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrieStartsWithEmptyPrefix(t *testing.T) {
+	trie := NewTrie()
+	if trie.StartsWith("") {
+		t.Error(`StartsWith("") on an empty Trie = true, want false`)
+	}
+	trie.Insert("a")
+	if !trie.StartsWith("") {
+		t.Error(`StartsWith("") with a stored word = false, want true`)
+	}
+	trie.Delete("a")
+	if trie.StartsWith("") {
+		t.Error(`StartsWith("") after deleting the only word = true, want false`)
+	}
+	trie.Insert("")
+	if !trie.StartsWith("") {
+		t.Error(`StartsWith("") with the empty word stored = false, want true`)
+	}
+}
+
+func TestTrieStartsWithIgnoresSoftDeletedPaths(t *testing.T) {
+	trie := newTrieWith("car", "card", "cat", "dog")
+	trie.Delete("card")
+	trie.Delete("dog")
+
+	tests := []struct {
+		prefix string
+		want   bool
+	}{
+		{"car", true},   // Still a stored word
+		{"card", false}, // Only word below it was deleted
+		{"ca", true},
+		{"d", false}, // Every word below it was deleted
+		{"do", false},
+		{"dog", false},
+		{"", true},
+	}
+	for _, tt := range tests {
+		if got := trie.StartsWith(tt.prefix); got != tt.want {
+			t.Errorf("StartsWith(%q) = %t, want %t", tt.prefix, got, tt.want)
+		}
+	}
+
+	trie.Insert("dot")
+	if !trie.StartsWith("do") || trie.StartsWith("dog") {
+		t.Errorf(`after re-inserting under "do": StartsWith("do") = %t, StartsWith("dog") = %t; want true, false`,
+			trie.StartsWith("do"), trie.StartsWith("dog"))
+	}
+}
+
+// TestStartsWithAgreesAcrossVariants checks that every Trie variant answers
+// StartsWith like Trie: false for the empty prefix while nothing is stored,
+// and false for a path whose words were all deleted. HybridTrie and RadixTrie
+// have no Delete, so they only store the words that survive.
+func TestStartsWithAgreesAcrossVariants(t *testing.T) {
+	inserted := []string{"car", "card", "dog"}
+	deleted := []string{"card", "dog"}
+	kept := []string{"car"}
+	variants := []struct {
+		name  string
+		build func(insert, remove []string) func(prefix string) bool
+	}{
+		{"Trie", func(insert, remove []string) func(string) bool {
+			trie := newTrieWith(insert...)
+			for _, word := range remove {
+				trie.Delete(word)
+			}
+			return trie.StartsWith
+		}},
+		{"RuneTrie", func(insert, remove []string) func(string) bool {
+			trie := NewRuneTrie()
+			for _, word := range insert {
+				trie.Insert(word)
+			}
+			for _, word := range remove {
+				trie.Delete(word)
+			}
+			return trie.StartsWith
+		}},
+		{"PersistentTrie", func(insert, remove []string) func(string) bool {
+			trie := NewPersistentTrie()
+			for _, word := range insert {
+				trie = trie.Insert(word)
+			}
+			for _, word := range remove {
+				trie, _ = trie.Delete(word)
+			}
+			return trie.StartsWith
+		}},
+		{"HybridTrie", func(insert, remove []string) func(string) bool {
+			trie := NewHybridTrie()
+			for _, word := range insert {
+				if !slices.Contains(remove, word) {
+					trie.Insert(word)
+				}
+			}
+			return trie.StartsWith
+		}},
+		{"RadixTrie", func(insert, remove []string) func(string) bool {
+			trie := NewRadixTrie()
+			for _, word := range insert {
+				if !slices.Contains(remove, word) {
+					trie.Insert(word)
+				}
+			}
+			return trie.StartsWith
+		}},
+	}
+	for _, v := range variants {
+		t.Run(v.name, func(t *testing.T) {
+			if v.build(nil, nil)("") {
+				t.Error(`StartsWith("") on an empty trie = true, want false`)
+			}
+			if v.build(kept, kept)("") {
+				t.Error(`StartsWith("") after deleting every word = true, want false`)
+			}
+
+			startsWith := v.build(inserted, deleted)
+			for prefix, want := range map[string]bool{"": true, "ca": true, "car": true, "card": false, "d": false, "dog": false} {
+				if got := startsWith(prefix); got != want {
+					t.Errorf("StartsWith(%q) = %t, want %t", prefix, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTrieCollectAllWordsStartingWith(t *testing.T) {
+	trie := newTrieWith(sampleWords...)
+	tests := []struct {
+		name   string
+		prefix string
+		want   []string
+	}{
+		{"everything", "", []string{"app", "apple", "application", "car", "card", "cat"}},
+		{"shared prefix", "ca", []string{"car", "card", "cat"}},
+		{"prefix is a word", "app", []string{"app", "apple", "application"}},
+		{"leaf word", "cat", []string{"cat"}},
+		{"missing", "z", []string{}},
+		{"longer than any word", "cats", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := trie.CollectAllWordsStartingWith(tt.prefix)
+			if got == nil {
+				t.Fatalf("CollectAllWordsStartingWith(%q) = nil, want non-nil", tt.prefix)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CollectAllWordsStartingWith(%q) = %q, want %q", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrieDelete(t *testing.T) {
+	tests := []struct {
+		name      string
+		words     []string
+		delete    string
+		want      bool
+		remaining []string
+	}{
+		{"leaf word", []string{"cat", "car"}, "cat", true, []string{"car"}},
+		{"word that is a prefix", []string{"app", "apple"}, "app", true, []string{"apple"}},
+		{"word with a stored prefix", []string{"app", "apple"}, "apple", true, []string{"app"}},
+		{"prefix only", []string{"apple"}, "app", false, []string{"apple"}},
+		{"nonexistent", []string{"apple"}, "banana", false, []string{"apple"}},
+		{"extends a stored word", []string{"app"}, "apple", false, []string{"app"}},
+		{"empty string stored", []string{"", "a"}, "", true, []string{"a"}},
+		{"empty string not stored", []string{"a"}, "", false, []string{"a"}},
+		{"only word", []string{"solo"}, "solo", true, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trie := newTrieWith(tt.words...)
+			if got := trie.Delete(tt.delete); got != tt.want {
+				t.Errorf("Delete(%q) = %t, want %t", tt.delete, got, tt.want)
+			}
+			checkTrieInvariant(t, trie)
+			if trie.Search(tt.delete) {
+				t.Errorf("Search(%q) after Delete = true, want false", tt.delete)
+			}
+			if got := trie.Words(); !slices.Equal(got, tt.remaining) {
+				t.Errorf("Words() after Delete(%q) = %q, want %q", tt.delete, got, tt.remaining)
+			}
+			if got := trie.CountWords(); got != len(tt.remaining) {
+				t.Errorf("CountWords() = %d, want %d", got, len(tt.remaining))
+			}
+		})
+	}
+}
+
+func TestTrieDeleteTwice(t *testing.T) {
+	trie := newTrieWith("cat")
+	if !trie.Delete("cat") {
+		t.Fatal(`first Delete("cat") = false, want true`)
+	}
+	if trie.Delete("cat") {
+		t.Error(`second Delete("cat") = true, want false`)
+	}
+}
+
+func TestTrieSoftDeleteThenReinsert(t *testing.T) {
+	trie := newTrieWith("apple", "app")
+	trie.Insert("app")
+	trie.Delete("app")
+
+	if trie.Search("app") {
+		t.Error(`Search("app") after Delete = true, want false`)
+	}
+	if !trie.StartsWith("app") {
+		t.Error(`StartsWith("app") = false, want true while "apple" is stored`)
+	}
+	if got := trie.Count("app"); got != 0 {
+		t.Errorf(`Count("app") after Delete = %d, want 0`, got)
+	}
+
+	trie.Insert("app")
+	checkTrieInvariant(t, trie)
+	if !trie.Search("app") {
+		t.Error(`Search("app") after re-insert = false, want true`)
+	}
+	if got := trie.Count("app"); got != 1 {
+		t.Errorf(`Count("app") after re-insert = %d, want 1 (delete resets the count)`, got)
+	}
+}
+
+func TestTrieInsertDuplicate(t *testing.T) {
+	trie := newTrieWith("go", "go", "go")
+	checkTrieInvariant(t, trie)
+	if got := trie.Count("go"); got != 3 {
+		t.Errorf(`Count("go") = %d, want 3`, got)
+	}
+	if got := trie.CountWords(); got != 1 {
+		t.Errorf("CountWords() = %d, want 1", got)
+	}
+	if got := trie.Words(); !slices.Equal(got, []string{"go"}) {
+		t.Errorf("Words() = %q, want [go]", got)
+	}
+}
+
+func TestTrieEmptyString(t *testing.T) {
+	trie := NewTrie()
+	if trie.Search("") {
+		t.Error(`Search("") on an empty Trie = true, want false`)
+	}
+	if trie.StartsWith("") {
+		t.Error(`StartsWith("") on an empty Trie = true, want false (no word starts with it)`)
+	}
+	if got := trie.CollectAllWordsStartingWith(""); len(got) != 0 {
+		t.Errorf(`CollectAllWordsStartingWith("") on an empty Trie = %q, want []`, got)
+	}
+
+	trie.Insert("")
+	checkTrieInvariant(t, trie)
+	if !trie.Search("") {
+		t.Error(`Search("") after Insert("") = false, want true`)
+	}
+	if got := trie.CollectAllWordsStartingWith(""); !slices.Equal(got, []string{""}) {
+		t.Errorf(`CollectAllWordsStartingWith("") = %q, want [""]`, got)
+	}
+}
+
+func TestTrieInvalidInput(t *testing.T) {
+	ops := []struct {
+		name string
+		call func(trie *Trie, s string)
+	}{
+		{"Insert", func(trie *Trie, s string) { trie.Insert(s) }},
+		{"Search", func(trie *Trie, s string) { trie.Search(s) }},
+		{"StartsWith", func(trie *Trie, s string) { trie.StartsWith(s) }},
+		{"Delete", func(trie *Trie, s string) { trie.Delete(s) }},
+		{"CollectAllWordsStartingWith", func(trie *Trie, s string) { trie.CollectAllWordsStartingWith(s) }},
+	}
+	inputs := []struct {
+		s    string
+		char byte
+		pos  int
+	}{
+		{"ApPle", 'A', 0},
+		{"hello world", ' ', 5},
+		{"abc1", '1', 3},
+	}
+
+	for _, op := range ops {
+		for _, in := range inputs {
+			t.Run(op.name+"/panics/"+in.s, func(t *testing.T) {
+				trie := newTrieWith("apple")
+				defer func() {
+					r := recover()
+					err, ok := r.(error)
+					if !ok {
+						t.Fatalf("%s(%q) recovered %v, want an InvalidCharError panic", op.name, in.s, r)
+					}
+					var ice InvalidCharError
+					if !errors.As(err, &ice) || ice.Char != in.char || ice.Pos != in.pos {
+						t.Errorf("%s(%q) panicked with %v, want %q at %d", op.name, in.s, err, in.char, in.pos)
+					}
+					checkTrieInvariant(t, trie)
+					if got := trie.Words(); !slices.Equal(got, []string{"apple"}) {
+						t.Errorf("Words() after panicking %s = %q, want [apple]", op.name, got)
+					}
+				}()
+				op.call(trie, in.s)
+			})
+		}
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		trie := newTrieWith("apple")
+		for _, in := range inputs {
+			if err := trie.InsertE(in.s); !errors.Is(err, ErrInvalidChar) {
+				t.Errorf("InsertE(%q) = %v, want ErrInvalidChar", in.s, err)
+			}
+			if _, err := trie.SearchE(in.s); !errors.Is(err, ErrInvalidChar) {
+				t.Errorf("SearchE(%q) error = %v, want ErrInvalidChar", in.s, err)
+			}
+			if _, err := trie.StartsWithE(in.s); !errors.Is(err, ErrInvalidChar) {
+				t.Errorf("StartsWithE(%q) error = %v, want ErrInvalidChar", in.s, err)
+			}
+			if _, err := trie.DeleteE(in.s); !errors.Is(err, ErrInvalidChar) {
+				t.Errorf("DeleteE(%q) error = %v, want ErrInvalidChar", in.s, err)
+			}
+		}
+		if got := trie.Words(); !slices.Equal(got, []string{"apple"}) {
+			t.Errorf("Words() after rejected input = %q, want [apple]", got)
+		}
+	})
+}
+
+func TestTrieApostropheAndHyphen(t *testing.T) {
+	trie := newTrieWith("don't", "dona", "mother-in-law", "mother")
+	for _, word := range []string{"don't", "mother-in-law"} {
+		if !trie.Search(word) {
+			t.Errorf("Search(%q) = false, want true", word)
+		}
+	}
+	want := []string{"don't", "dona"} // '\'' sorts before 'a'
+	if got := trie.CollectAllWordsStartingWith("don"); !slices.Equal(got, want) {
+		t.Errorf(`CollectAllWordsStartingWith("don") = %q, want %q`, got, want)
+	}
+}
+
+func TestTrieWordList(t *testing.T) {
+	words := loadWords(t, "words.txt")
+	trie := newTrieWith(words...)
+	checkTrieInvariant(t, trie)
+	if err := trie.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	want := slices.Clone(words)
+	slices.Sort(want)
+	want = slices.Compact(want)
+	if got := trie.Words(); !slices.Equal(got, want) {
+		t.Fatalf("Words() = %q, want the sorted word list", got)
+	}
+	for _, word := range words {
+		if !trie.Search(word) {
+			t.Errorf("Search(%q) = false, want true", word)
+		}
+		for i := range len(word) {
+			if !trie.StartsWith(word[:i]) {
+				t.Errorf("StartsWith(%q) = false, want true", word[:i])
+			}
+		}
+	}
+}
+
+// TestTrieRandomOps replays random inserts and deletes against a map and
+// checks that the Trie agrees with it after every step.
+func TestTrieRandomOps(t *testing.T) {
+	rng := newTestRand(142)
+	trie := NewTrie()
+	model := make(map[string]int)
+	for step := range 2000 {
+		word := randomWord(rng, 0, 5, 3)
+		if rng.IntN(3) == 0 {
+			_, stored := model[word]
+			if got := trie.Delete(word); got != stored {
+				t.Fatalf("step %d: Delete(%q) = %t, want %t", step, word, got, stored)
+			}
+			delete(model, word)
+		} else {
+			trie.Insert(word)
+			model[word]++
+		}
+
+		probe := randomWord(rng, 0, 5, 3)
+		if got := trie.Search(probe); got != (model[probe] > 0) {
+			t.Fatalf("step %d: Search(%q) = %t, want %t", step, probe, got, model[probe] > 0)
+		}
+		if got := trie.Count(probe); got != model[probe] {
+			t.Fatalf("step %d: Count(%q) = %d, want %d", step, probe, got, model[probe])
+		}
+	}
+	checkTrieInvariant(t, trie)
+	if got := trie.CountWords(); got != len(model) {
+		t.Errorf("CountWords() = %d, want %d", got, len(model))
+	}
+}
+
+// FuzzSoftVsHardDelete applies the same inserts and deletes to two tries, one
+// deleting with Delete and one with HardDelete, and checks after every
+// operation that Search, StartsWith and Words agree: hard delete may only
+// change memory use, never what the Trie reports. The input is a
+// space-separated script of "+word" (insert) and "-word" (delete) operations;
+// anything outside the alphabet is dropped from the words.
+func FuzzSoftVsHardDelete(f *testing.F) {
+	for _, seed := range []string{
+		"+a +ab +abc -ab -abc -a",
+		"+app +apple +application -apple -app +apply -application",
+		"+car +card +care +careful -card -careful -care +ca -car",
+		"+ -  +a -a",
+		"+don't +dona +don -don't -don",
+		"+mother +mother-in-law -mother-in-law +mother-in -mother",
+		"+abc +abc -abc -abc +ab -ab",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, script string) {
+		ops := strings.Fields(script)
+		if len(ops) > 64 {
+			t.Skip("script too long") // Keep each input fast; longer scripts add nothing new
+		}
+		soft, hard := NewTrie(), NewTrie()
+		probes := make(map[string]bool)
+		for step, op := range ops {
+			word := lowerAndKeep(op[1:], isAlphabetChar)
+			switch op[0] {
+			case '+':
+				soft.Insert(word)
+				hard.Insert(word)
+			case '-':
+				softFound := soft.Delete(word)
+				hardFound, _ := hard.HardDelete(word)
+				if softFound != hardFound {
+					t.Fatalf("step %d (%s): Delete = %t, HardDelete found = %t", step, op, softFound, hardFound)
+				}
+			default:
+				continue
+			}
+			for i := range len(word) + 1 {
+				probes[word[:i]] = true
+			}
+
+			for p := range probes {
+				if s, h := soft.Search(p), hard.Search(p); s != h {
+					t.Fatalf("step %d (%s): Search(%q) soft = %t, hard = %t", step, op, p, s, h)
+				}
+				if s, h := soft.StartsWith(p), hard.StartsWith(p); s != h {
+					t.Fatalf("step %d (%s): StartsWith(%q) soft = %t, hard = %t", step, op, p, s, h)
+				}
+			}
+			if s, h := soft.Words(), hard.Words(); !slices.Equal(s, h) {
+				t.Fatalf("step %d (%s): Words() soft = %q, hard = %q", step, op, s, h)
+			}
+			checkTrieInvariant(t, soft)
+			checkTrieInvariant(t, hard)
+			if err := hard.Validate(); err != nil {
+				t.Fatalf("step %d (%s): hard-deleting Trie left dead nodes: %v", step, op, err)
+			}
+		}
+	})
+}
+
+func TestSearchStartsWithDoNotAllocate(t *testing.T) {
+	trie := newTrieWith(loadWords(t, "words.txt")...)
+	queries := []string{"application", "mother-in-law", "don't", "appl", "zebra", ""}
+	for _, q := range queries {
+		if n := testing.AllocsPerRun(100, func() { trie.Search(q) }); n != 0 {
+			t.Errorf("Search(%q) allocates %v times per call, want 0", q, n)
+		}
+		if n := testing.AllocsPerRun(100, func() { trie.StartsWith(q) }); n != 0 {
+			t.Errorf("StartsWith(%q) allocates %v times per call, want 0", q, n)
+		}
+	}
+
+	folding := NewTrieCaseInsensitive()
+	folding.Insert("apple")
+	if n := testing.AllocsPerRun(100, func() { folding.Search("apple") }); n != 0 {
+		t.Errorf("case-insensitive Search of lowercase input allocates %v times per call, want 0", n)
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	words := loadWords(b, "words.txt")
+	trie := newTrieWith(words...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trie.Search(words[i%len(words)])
+	}
+}
+
+func BenchmarkSearchMiss(b *testing.B) {
+	words := loadWords(b, "words.txt")
+	trie := newTrieWith(words...)
+	misses := make([]string, len(words))
+	for i, word := range words {
+		misses[i] = word + "zz"
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trie.Search(misses[i%len(misses)])
+	}
+}
+
+func BenchmarkStartsWith(b *testing.B) {
+	words := loadWords(b, "words.txt")
+	trie := newTrieWith(words...)
+	prefixes := make([]string, len(words))
+	for i, word := range words {
+		prefixes[i] = word[:(len(word)+1)/2]
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trie.StartsWith(prefixes[i%len(prefixes)])
+	}
+}
+
+// TestTrieCaseInsensitiveEverywhere checks that every entry point folds case
+// the same way Search does, instead of panicking or missing uppercase input.
+func TestTrieCaseInsensitiveEverywhere(t *testing.T) {
+	trie := NewTrieCaseInsensitive()
+	for _, word := range []string{"Cat", "CAR", "card", "Apple"} {
+		trie.Insert(word)
+	}
+	checkTrieInvariant(t, trie)
+
+	if got := trie.Words(); !slices.Equal(got, []string{"apple", "car", "card", "cat"}) {
+		t.Fatalf("Words() = %q, want the lowercased words", got)
+	}
+	for _, q := range []string{"cat", "CAT", "cAt"} {
+		if !trie.Search(q) {
+			t.Errorf("Search(%q) = false, want true", q)
+		}
+		if got := trie.Count(q); got != 1 {
+			t.Errorf("Count(%q) = %d, want 1", q, got)
+		}
+		if depth, found := trie.SearchDepth(q); depth != 3 || !found {
+			t.Errorf("SearchDepth(%q) = %d, %t, want 3, true", q, depth, found)
+		}
+		if p, ok := trie.UniquePrefixOf(q); p != "cat" || !ok {
+			t.Errorf("UniquePrefixOf(%q) = %q, %t, want \"cat\", true", q, p, ok)
+		}
+	}
+	if got := trie.Lookup("CA"); got != Prefix {
+		t.Errorf(`Lookup("CA") = %v, want Prefix`, got)
+	}
+	if got := trie.Lookup("CAR"); got != WordAndPrefix {
+		t.Errorf(`Lookup("CAR") = %v, want WordAndPrefix`, got)
+	}
+	if got := trie.CollectWithPrefixPaged("CA", 0, 5); !slices.Equal(got, []string{"car", "card", "cat"}) {
+		t.Errorf(`CollectWithPrefixPaged("CA", 0, 5) = %q`, got)
+	}
+	if got, err := trie.CollectWithPrefixCtx(context.Background(), "Ca", 0); err != nil || len(got) != 3 {
+		t.Errorf(`CollectWithPrefixCtx("Ca") = %q, %v, want 3 words`, got, err)
+	}
+	if got := trie.NextCharWeights("CA"); got['r'] != 2 || got['t'] != 1 || len(got) != 2 {
+		t.Errorf(`NextCharWeights("CA") = %v, want map[r:2 t:1]`, got)
+	}
+	if got := trie.SearchAll([]string{"CAT", "dog"}); !got["CAT"] || got["dog"] {
+		t.Errorf(`SearchAll([CAT dog]) = %v, want CAT true, dog false`, got)
+	}
+	if !trie.AnyStartsWith([]string{"ZZ", "AP"}) || !trie.AllStartWith([]string{"Ca", "APP"}) {
+		t.Error("AnyStartsWith/AllStartWith don't fold uppercase prefixes")
+	}
+	if !trie.SearchPattern("C.T") || trie.CountPatternMatches("CA.") != 2 {
+		t.Error("SearchPattern/CountPatternMatches don't fold uppercase patterns")
+	}
+	if got, ok := trie.LongestPrefixOf("CARDS"); got != "card" || !ok {
+		t.Errorf(`LongestPrefixOf("CARDS") = %q, %t, want "card", true`, got, ok)
+	}
+	if got := trie.SearchFuzzy("CAT", 0); !slices.Equal(got, []string{"cat"}) {
+		t.Errorf(`SearchFuzzy("CAT", 0) = %q, want [cat]`, got)
+	}
+	if got := trie.SearchWithinDamerauDistance("ACT", 1); !slices.Equal(got, []string{"cat"}) {
+		t.Errorf(`SearchWithinDamerauDistance("ACT", 1) = %q, want [cat]`, got)
+	}
+	if got := trie.FuzzyComplete("APLE", 1, 5); !slices.Equal(got, []string{"apple"}) {
+		t.Errorf(`FuzzyComplete("APLE", 1, 5) = %q, want [apple]`, got)
+	}
+
+	if !trie.Delete("CAT") || trie.Search("cat") {
+		t.Error(`Delete("CAT") didn't remove "cat"`)
+	}
+	checkTrieInvariant(t, trie)
+}
+
+// TestTrieInputPolicies runs every method that takes a word or prefix on input
+// with a character outside the alphabet, under each of the three policies.
+func TestTrieInputPolicies(t *testing.T) {
+	ops := []struct {
+		name string
+		call func(trie *Trie, s string) any
+	}{
+		{"Insert", func(trie *Trie, s string) any { trie.Insert(s); return nil }},
+		{"InsertAll", func(trie *Trie, s string) any { return trie.InsertAll([]string{s}) }},
+		{"Search", func(trie *Trie, s string) any { return trie.Search(s) }},
+		{"SearchDepth", func(trie *Trie, s string) any { d, ok := trie.SearchDepth(s); return []any{d, ok} }},
+		{"StartsWith", func(trie *Trie, s string) any { return trie.StartsWith(s) }},
+		{"PrefixCount", func(trie *Trie, s string) any { return trie.PrefixCount(s) }},
+		{"Count", func(trie *Trie, s string) any { return trie.Count(s) }},
+		{"Lookup", func(trie *Trie, s string) any { return trie.Lookup(s) }},
+		{"Delete", func(trie *Trie, s string) any { return trie.Delete(s) }},
+		{"HardDelete", func(trie *Trie, s string) any { f, p := trie.HardDelete(s); return []any{f, p} }},
+		{"DeletePrefix", func(trie *Trie, s string) any { return trie.DeletePrefix(s) }},
+		{"CollectAllWordsStartingWith", func(trie *Trie, s string) any { return trie.CollectAllWordsStartingWith(s) }},
+		{"CollectWithPrefixPaged", func(trie *Trie, s string) any { return trie.CollectWithPrefixPaged(s, 0, 10) }},
+		{"CollectWithPrefixCtx", func(trie *Trie, s string) any {
+			words, err := trie.CollectWithPrefixCtx(context.Background(), s, 0)
+			return []any{words, err}
+		}},
+		{"Suggest", func(trie *Trie, s string) any { return trie.Suggest(s, 10) }},
+		{"NextCharWeights", func(trie *Trie, s string) any { return trie.NextCharWeights(s) }},
+		{"UniquePrefixOf", func(trie *Trie, s string) any { p, ok := trie.UniquePrefixOf(s); return []any{p, ok} }},
+	}
+	const invalid, stripped, missing = "c@t", "ct", "q"
+	stored := []string{"cat", "ct", "cta"}
+
+	for _, op := range ops {
+		t.Run("PanicOnInvalid/"+op.name, func(t *testing.T) {
+			trie := newTrieWith(stored...)
+			defer func() {
+				err, _ := recover().(error)
+				var ice InvalidCharError
+				if !errors.As(err, &ice) || ice.Char != '@' || ice.Pos != 1 {
+					t.Errorf("%s(%q) panicked with %v, want InvalidCharError for '@' at 1", op.name, invalid, err)
+				}
+				if got := trie.Words(); !slices.Equal(got, stored) {
+					t.Errorf("Words() after %s = %q, want %q", op.name, got, stored)
+				}
+			}()
+			op.call(trie, invalid)
+		})
+
+		t.Run("RejectInvalid/"+op.name, func(t *testing.T) {
+			trie := NewTrieFromWords(stored, WithInputPolicy(RejectInvalid))
+			got := op.call(trie, invalid)
+			miss := missing
+			if strings.HasPrefix(op.name, "Insert") {
+				miss = stored[0] // Adds no new word, as a rejected insert mustn't
+			}
+			want := op.call(NewTrieFromWords(stored), miss) // What a plain miss returns
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s(%q) = %v, want %v", op.name, invalid, got, want)
+			}
+			if words := trie.Words(); !slices.Equal(words, stored) {
+				t.Errorf("Words() after %s = %q, want %q", op.name, words, stored)
+			}
+			checkTrieInvariant(t, trie)
+		})
+
+		t.Run("SkipInvalid/"+op.name, func(t *testing.T) {
+			trie := NewTrieFromWords(stored, WithInputPolicy(SkipInvalid))
+			want := NewTrieFromWords(stored)
+			got, wantResult := op.call(trie, invalid), op.call(want, stripped)
+			if !reflect.DeepEqual(got, wantResult) {
+				t.Errorf("%s(%q) = %v, want %v as for %q", op.name, invalid, got, wantResult, stripped)
+			}
+			if !trie.Equal(want) {
+				t.Errorf("Words() after %s = %q, want %q", op.name, trie.Words(), want.Words())
+			}
+			checkTrieInvariant(t, trie)
+		})
+	}
+
+	t.Run("RejectInvalid/E methods", func(t *testing.T) {
+		trie := NewTrieFromWords(stored, WithInputPolicy(RejectInvalid))
+		if err := trie.InsertE(invalid); !errors.Is(err, ErrInvalidChar) {
+			t.Errorf("InsertE(%q) = %v, want ErrInvalidChar", invalid, err)
+		}
+		if _, err := trie.SearchE(invalid); !errors.Is(err, ErrInvalidChar) {
+			t.Errorf("SearchE(%q) error = %v, want ErrInvalidChar", invalid, err)
+		}
+		if _, err := trie.StartsWithE(invalid); !errors.Is(err, ErrInvalidChar) {
+			t.Errorf("StartsWithE(%q) error = %v, want ErrInvalidChar", invalid, err)
+		}
+		if _, err := trie.DeleteE(invalid); !errors.Is(err, ErrInvalidChar) {
+			t.Errorf("DeleteE(%q) error = %v, want ErrInvalidChar", invalid, err)
+		}
+	})
+
+	t.Run("SkipInvalid/E methods", func(t *testing.T) {
+		trie := NewTrie(WithStrictInput(false))
+		if err := trie.InsertE("Don't!"); err != nil {
+			t.Fatalf(`InsertE("Don't!") = %v, want nil`, err)
+		}
+		if found, err := trie.SearchE("on't"); !found || err != nil {
+			t.Errorf(`SearchE("on't") = %t, %v, want true, nil`, found, err)
+		}
+	})
+
+	t.Run("BuildFromSorted", func(t *testing.T) {
+		words := []string{"ab", "a!c", "b"}
+		if got := BuildFromSorted(words, WithInputPolicy(RejectInvalid)).Words(); !slices.Equal(got, []string{"ab", "b"}) {
+			t.Errorf("RejectInvalid: Words() = %q, want [ab b]", got)
+		}
+		if got := BuildFromSorted(words, WithInputPolicy(SkipInvalid)).Words(); !slices.Equal(got, []string{"ab", "ac", "b"}) {
+			t.Errorf("SkipInvalid: Words() = %q, want [ab ac b]", got)
+		}
+		defer func() {
+			if err, _ := recover().(error); !errors.Is(err, ErrInvalidChar) {
+				t.Errorf("PanicOnInvalid: recovered %v, want an InvalidCharError", err)
+			}
+		}()
+		BuildFromSorted(words)
+	})
+}
+
+func TestValueTrieInvalidInputPanicsWithInvalidCharError(t *testing.T) {
+	calls := map[string]func(vt *ValueTrie[int]){
+		"Insert": func(vt *ValueTrie[int]) { vt.Insert("a b", 1) },
+		"Get":    func(vt *ValueTrie[int]) { vt.Get("A") },
+		"Rename": func(vt *ValueTrie[int]) { vt.Rename("ab", "a!") },
+		"Collect": func(vt *ValueTrie[int]) {
+			vt.CollectAllWordsStartingWith("1")
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			vt := NewValueTrie[int]()
+			vt.Insert("ab", 1)
+			defer func() {
+				if err, _ := recover().(error); !errors.Is(err, ErrInvalidChar) {
+					t.Errorf("recovered %v, want an InvalidCharError", err)
+				}
+				if v, ok := vt.Get("ab"); v != 1 || !ok {
+					t.Errorf(`Get("ab") = %d, %t after the panic, want 1, true`, v, ok)
+				}
+			}()
+			call(vt)
+		})
+	}
+}
+
+func TestSearchWithinDamerauDistance(t *testing.T) {
+	trie := newTrieWith("the", "then", "tea", "ten", "hte", "a")
+	tests := []struct {
+		word    string
+		maxDist int
+		want    []string
+	}{
+		{"teh", 1, []string{"tea", "ten", "the"}}, // "the" by one swap
+		{"teh", 0, []string{}},
+		{"hten", 1, []string{"hte", "ten", "then"}},
+		{"the", -1, []string{}},
+		{"", 1, []string{"a"}},
+		{"xyz", 1, []string{}},
+	}
+	for _, tt := range tests {
+		got := trie.SearchWithinDamerauDistance(tt.word, tt.maxDist)
+		if got == nil || !slices.Equal(got, tt.want) {
+			t.Errorf("SearchWithinDamerauDistance(%q, %d) = %#v, want %q", tt.word, tt.maxDist, got, tt.want)
+		}
+	}
+
+	if got := trie.SearchFuzzy("teh", 1); slices.Contains(got, "the") {
+		t.Errorf(`SearchFuzzy("teh", 1) = %q; plain Levenshtein must not count the swap as 1`, got)
+	}
+}
+
+func TestSearchWithinDamerauDistanceEmptyWord(t *testing.T) {
+	trie := newTrieWith("", "a")
+	if got := trie.SearchWithinDamerauDistance("-", 1); !slices.Equal(got, []string{"", "a"}) {
+		t.Errorf(`SearchWithinDamerauDistance("-", 1) = %q, want ["" a]`, got)
+	}
+	if got := trie.SearchWithinDamerauDistance("", 0); !slices.Equal(got, []string{""}) {
+		t.Errorf(`SearchWithinDamerauDistance("", 0) = %q, want [""]`, got)
+	}
+}
+
+// osaDistance is a straightforward optimal string alignment distance, used
+// as the reference for the incremental DFS in SearchWithinDamerauDistance.
+func osaDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func TestSearchWithinDamerauDistanceMatchesBruteForce(t *testing.T) {
+	rng := newTestRand(120)
+	words := randomWords(rng, 300, 0, 6, 4)
+	trie := newTrieWith(words...)
+	stored := trie.Words()
+	for range 200 {
+		query := randomWord(rng, 0, 6, 4)
+		maxDist := rng.IntN(3)
+		want := []string{}
+		for _, w := range stored {
+			if osaDistance(query, w) <= maxDist {
+				want = append(want, w)
+			}
+		}
+		if got := trie.SearchWithinDamerauDistance(query, maxDist); !slices.Equal(got, want) {
+			t.Fatalf("SearchWithinDamerauDistance(%q, %d) = %q, want %q", query, maxDist, got, want)
+		}
+	}
+}
+
+// TestCollectAllWordsStartingWithStrictlySorted pins the ordering contract
+// documented on CollectAllWordsStartingWith, for the Trie and the variants
+// that promise the same order.
+func TestCollectAllWordsStartingWithStrictlySorted(t *testing.T) {
+	words := loadWords(t, "words.txt")
+	words = append(words, randomWords(newTestRand(125), 500, 1, 8, 5)...)
+
+	trie := newTrieWith(words...)
+	runes := NewRuneTrie()
+	radix := NewRadixTrie()
+	persistent := NewPersistentTrie()
+	for _, word := range words {
+		runes.Insert(word)
+		radix.Insert(word)
+		persistent = persistent.Insert(word)
+	}
+	collectors := map[string]func(prefix string) []string{
+		"Trie":           trie.CollectAllWordsStartingWith,
+		"RuneTrie":       runes.CollectAllWordsStartingWith,
+		"RadixTrie":      radix.CollectAllWordsStartingWith,
+		"PersistentTrie": persistent.CollectAllWordsStartingWith,
+	}
+
+	for _, prefix := range []string{"", "a", "ab", "app", "c", "ca", "mother", "d", "e", "zzz"} {
+		for name, collect := range collectors {
+			got := collect(prefix)
+			for i := 1; i < len(got); i++ {
+				if got[i-1] >= got[i] {
+					t.Fatalf("%s: CollectAllWordsStartingWith(%q) is not strictly increasing at %d: %q >= %q",
+						name, prefix, i, got[i-1], got[i])
+				}
+			}
+		}
+	}
+}
+
+func TestBuildFromSortedMatchesInsertAll(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+	}{
+		{"empty", nil},
+		{"single", []string{"a"}},
+		{"shared prefixes", []string{"a", "ab", "abc", "abd", "b", "ba"}},
+		{"repeats", []string{"a", "a", "ab", "ab", "ab"}},
+		{"empty word", []string{"", "a"}},
+		{"unsorted", []string{"cat", "apple", "car", "app", "cat"}},
+		{"word list", loadWords(t, "words.txt")},
+		{"random", randomWords(newTestRand(104), 500, 0, 7, 4)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := slices.Clone(tt.words)
+			if tt.name != "unsorted" {
+				slices.Sort(sorted)
+			}
+			built := BuildFromSorted(sorted)
+			inserted := NewTrie()
+			inserted.InsertAll(sorted)
+
+			checkTrieInvariant(t, built)
+			if err := built.Validate(); err != nil {
+				t.Fatalf("Validate() = %v", err)
+			}
+			if !built.Equal(inserted) || !slices.Equal(built.Words(), inserted.Words()) {
+				t.Fatalf("BuildFromSorted holds %q, InsertAll holds %q", built.Words(), inserted.Words())
+			}
+			for _, word := range sorted {
+				if built.Count(word) != inserted.Count(word) {
+					t.Errorf("Count(%q) = %d, want %d", word, built.Count(word), inserted.Count(word))
+				}
+			}
+			if built.NodeCount() != inserted.NodeCount() {
+				t.Errorf("NodeCount() = %d, want %d", built.NodeCount(), inserted.NodeCount())
+			}
+		})
+	}
+}
+
+// sortedDictionary returns a sorted, duplicate-free word list big enough for
+// the construction benchmarks.
+func sortedDictionary(tb testing.TB) []string {
+	words := append(loadWords(tb, "words.txt"), randomWords(newTestRand(104), 20000, 3, 10, 26)...)
+	slices.Sort(words)
+	return slices.Compact(words)
+}
+
+func BenchmarkBuildFromSorted(b *testing.B) {
+	words := sortedDictionary(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		BuildFromSorted(words)
+	}
+}
+
+func BenchmarkInsertAllSorted(b *testing.B) {
+	words := sortedDictionary(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewTrie().InsertAll(words)
+	}
+}
+
+func TestEstimatedBytesScalesWithNodeCount(t *testing.T) {
+	nodeSize := int(unsafe.Sizeof(Node{}))
+	trie := NewTrie()
+	base := trie.EstimatedBytes()
+	if base != nodeSize+int(unsafe.Sizeof(Trie{})) {
+		t.Fatalf("empty Trie EstimatedBytes() = %d, want one node plus the header", base)
+	}
+
+	// Each step adds a word one letter longer than the last, so exactly one node.
+	word := ""
+	for n := 1; n <= 50; n++ {
+		word += "a"
+		trie.Insert(word)
+		if got, want := trie.EstimatedBytes(), base+n*nodeSize; got != want {
+			t.Fatalf("after %d nodes EstimatedBytes() = %d, want %d", n, got, want)
+		}
+	}
+
+	// Disjoint words of length 3 add three nodes each.
+	before := trie.EstimatedBytes()
+	trie.InsertAll([]string{"bcd", "efg", "hij"})
+	if got, want := trie.EstimatedBytes()-before, 9*nodeSize; got != want {
+		t.Errorf("three disjoint 3-letter words added %d bytes, want %d", got, want)
+	}
+}
+
+func TestTrieInsertNGrams(t *testing.T) {
+	trie := NewTrie()
+	trie.InsertNGrams("The cat, the HAT! a", 2)
+
+	want := map[string]int{"th": 2, "he": 2, "ca": 1, "at": 2, "ha": 1}
+	got := make(map[string]int)
+	for _, gram := range trie.Words() {
+		got[gram] = trie.Count(gram)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bigram counts = %v, want %v", got, want)
+	}
+
+	for _, n := range []int{0, -1, 10} {
+		empty := NewTrie()
+		empty.InsertNGrams("the cat", n)
+		if empty.CountWords() != 0 {
+			t.Errorf("InsertNGrams(_, %d) stored %q, want nothing", n, empty.Words())
+		}
+	}
+}
+
+func TestTrieInsertText(t *testing.T) {
+	trie := NewTrie()
+	trie.InsertText("The cat sat. The cat, it's said, sat on the mat!\n  Mother-in-law's cat2 sat?")
+
+	for word, want := range map[string]int{
+		"the":             3,
+		"cat":             3, // "cat2" is stripped to "cat"
+		"sat":             3,
+		"it's":            1,
+		"mother-in-law's": 1,
+		"mat":             1,
+		"dog":             0,
+		"":                0,
+	} {
+		if got := trie.Count(word); got != want {
+			t.Errorf("Count(%q) = %d, want %d", word, got, want)
+		}
+	}
+	if err := trie.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestTrieSearchAll(t *testing.T) {
+	trie := newTrieWith(sampleWords...)
+	queries := []string{"cat", "ca", "apple", "dog", "Cat", "c4t", "", "cat"}
+	want := map[string]bool{
+		"cat":   true,
+		"ca":    false, // Only a prefix
+		"apple": true,
+		"dog":   false,
+		"Cat":   false, // Invalid input maps to false instead of panicking
+		"c4t":   false,
+		"":      false,
+	}
+	if got := trie.SearchAll(queries); !reflect.DeepEqual(got, want) {
+		t.Errorf("SearchAll(%q) = %v, want %v", queries, got, want)
+	}
+	if got := trie.SearchAll(nil); got == nil || len(got) != 0 {
+		t.Errorf("SearchAll(nil) = %v, want an empty map", got)
+	}
+}
+
+func TestTrieCheckpointRestore(t *testing.T) {
+	trie := newTrieWith("cat", "car", "cat")
+	checkpoint := trie.Checkpoint()
+
+	trie.Insert("card")
+	trie.Insert("dog")
+	trie.Insert("cat")
+	trie.Delete("car")
+
+	trie.Restore(checkpoint)
+	if got, want := trie.Words(), []string{"car", "cat"}; !slices.Equal(got, want) {
+		t.Fatalf("Words() after Restore = %q, want %q", got, want)
+	}
+	for word, want := range map[string]int{"cat": 2, "car": 1, "card": 0, "dog": 0} {
+		if got := trie.Count(word); got != want {
+			t.Errorf("Count(%q) after Restore = %d, want %d", word, got, want)
+		}
+	}
+	if trie.StartsWith("do") {
+		t.Error(`StartsWith("do") after Restore = true, want false`)
+	}
+	if err := trie.Validate(); err != nil {
+		t.Errorf("Validate() after Restore = %v", err)
+	}
+
+	// The checkpoint is unaffected by changes made after restoring it.
+	trie.Insert("dog")
+	trie.Restore(checkpoint)
+	if trie.Search("dog") {
+		t.Error(`Search("dog") after restoring the same checkpoint again = true, want false`)
+	}
+
+	empty := NewTrie().Checkpoint()
+	trie.Restore(empty)
+	if trie.CountWords() != 0 || trie.StartsWith("") {
+		t.Errorf("restoring an empty checkpoint left %q", trie.Words())
+	}
+}
+
+func TestTrieFuzzyComplete(t *testing.T) {
+	trie := newTrieWith("application", "applicable", "applicable", "apply", "apple", "aplomb", "banana")
+	tests := []struct {
+		prefix         string
+		maxDist, limit int
+		want           []string
+	}{
+		{"aplication", 1, 10, []string{"application"}},
+		{"aplic", 1, 10, []string{"applicable", "application"}}, // Ranked by count
+		{"aplic", 1, 1, []string{"applicable"}},
+		{"applx", 1, 10, []string{"applicable", "apple", "application", "apply"}},
+		{"apl", 1, 10, []string{"aplomb", "applicable", "apple", "application", "apply"}}, // Exact prefix first
+		{"bnana", 1, 10, []string{"banana"}},
+		{"xyz", 1, 10, []string{}},
+		{"aplic", 0, 10, []string{}},
+		{"aplic", 1, 0, []string{}},
+	}
+	for _, tt := range tests {
+		got := trie.FuzzyComplete(tt.prefix, tt.maxDist, tt.limit)
+		if !slices.Equal(got, tt.want) || got == nil {
+			t.Errorf("FuzzyComplete(%q, %d, %d) = %q, want %q", tt.prefix, tt.maxDist, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestTrieValidateReportsCorruption(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(trie *Trie)
+		wantErr string
+	}{
+		{"orphan leaf", func(trie *Trie) {
+			trie.root.children[charToIndex('z')] = NewNode()
+		}, "orphan"},
+		{"end without count", func(trie *Trie) {
+			trie.find("car").count = 0
+		}, "isEndOfWord=true but count=0"},
+		{"stale wordCount", func(trie *Trie) {
+			trie.find("ca").wordCount++
+		}, "wordCount"},
+		{"shared node", func(trie *Trie) {
+			trie.root.children[charToIndex('b')] = trie.find("c")
+		}, "more than once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trie := newTrieWith("car", "cat", "card")
+			if err := trie.Validate(); err != nil {
+				t.Fatalf("Validate() before corrupting = %v", err)
+			}
+			tt.corrupt(trie)
+			err := trie.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestTrieRandomOpsValidate replays random inserts and hard deletes and runs
+// Validate after every one, so a delete that leaves an orphan fails at once.
+func TestTrieRandomOpsValidate(t *testing.T) {
+	rng := newTestRand(122)
+	trie := NewTrie()
+	for step := range 2000 {
+		word := randomWord(rng, 0, 6, 3)
+		op := "Insert"
+		if rng.IntN(2) == 0 {
+			op = "HardDelete"
+			trie.HardDelete(word)
+		} else {
+			trie.Insert(word)
+		}
+		if err := trie.Validate(); err != nil {
+			t.Fatalf("step %d: Validate() after %s(%q) = %v", step, op, word, err)
+		}
+	}
+}
+
+func TestTrieLookup(t *testing.T) {
+	trie := newTrieWith("app", "apple", "cat", "")
+	trie.Insert("dog")
+	trie.Delete("dog") // Soft delete leaves the path behind
+	tests := []struct {
+		s    string
+		want LookupResult
+	}{
+		{"app", WordAndPrefix},
+		{"apple", Word},
+		{"ap", Prefix},
+		{"a", Prefix},
+		{"cat", Word},
+		{"cats", NotFound},
+		{"b", NotFound},
+		{"", WordAndPrefix},
+		{"do", NotFound}, // Only leads to a deleted word
+		{"dog", NotFound},
+	}
+	for _, tt := range tests {
+		got := trie.Lookup(tt.s)
+		if got != tt.want {
+			t.Errorf("Lookup(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+		if wantSearch := got == Word || got == WordAndPrefix; trie.Search(tt.s) != wantSearch {
+			t.Errorf("Search(%q) = %t disagrees with Lookup = %v", tt.s, !wantSearch, got)
+		}
+	}
+	if got := LookupResult(9).String(); got != "LookupResult(9)" {
+		t.Errorf("LookupResult(9).String() = %q", got)
+	}
+}
+
+func TestTrieCollectWithPrefixPaged(t *testing.T) {
+	rng := newTestRand(129)
+	trie := newTrieWith(randomWords(rng, 500, 1, 6, 4)...)
+	trie.Insert("zzz") // Outside every "a" page
+	want := trie.CollectAllWordsStartingWith("a")
+
+	for _, limit := range []int{1, 7, 50, len(want) + 1} {
+		var pages []string
+		for offset := 0; ; offset += limit {
+			page := trie.CollectWithPrefixPaged("a", offset, limit)
+			if len(page) > limit {
+				t.Fatalf("page at offset %d has %d words, limit %d", offset, len(page), limit)
+			}
+			if len(page) == 0 {
+				break
+			}
+			pages = append(pages, page...)
+		}
+		if !slices.Equal(pages, want) {
+			t.Errorf("limit %d: concatenated pages differ from CollectAllWordsStartingWith (got %d words, want %d)", limit, len(pages), len(want))
+		}
+	}
+
+	for _, tt := range []struct {
+		prefix        string
+		offset, limit int
+	}{
+		{"a", len(want), 10},
+		{"a", len(want) + 100, 10},
+		{"a", 0, 0},
+		{"a", -1, 10},
+		{"q", 0, 10},
+	} {
+		if got := trie.CollectWithPrefixPaged(tt.prefix, tt.offset, tt.limit); got == nil || len(got) != 0 {
+			t.Errorf("CollectWithPrefixPaged(%q, %d, %d) = %q, want []", tt.prefix, tt.offset, tt.limit, got)
+		}
+	}
+}
+
+func TestTrieSearchDepth(t *testing.T) {
+	trie := newTrieWith("apple", "apply", "cat")
+	tests := []struct {
+		word      string
+		wantDepth int
+		wantFound bool
+	}{
+		{"apple", 5, true},
+		{"app", 3, false}, // Whole path exists but isn't a word
+		{"apricot", 2, false},
+		{"applesauce", 5, false},
+		{"cow", 1, false},
+		{"dog", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		depth, found := trie.SearchDepth(tt.word)
+		if depth != tt.wantDepth || found != tt.wantFound {
+			t.Errorf("SearchDepth(%q) = %d, %t; want %d, %t", tt.word, depth, found, tt.wantDepth, tt.wantFound)
+		}
+	}
+}
+
+func TestTrieTotalPrefixesAndUniquePrefixOf(t *testing.T) {
+	trie := newTrieWith("car", "card", "care", "cat", "dog")
+	// c ca car card care cat d do dog
+	if got := trie.TotalPrefixes(); got != 9 {
+		t.Errorf("TotalPrefixes() = %d, want 9", got)
+	}
+	if got := trie.NodeCount() - 1; got != trie.TotalPrefixes() {
+		t.Errorf("NodeCount()-1 = %d, want TotalPrefixes() with no soft deletes", got)
+	}
+
+	tests := []struct {
+		word   string
+		want   string
+		wantOK bool
+	}{
+		{"dog", "d", true},
+		{"cat", "cat", true},
+		{"card", "card", true},
+		{"care", "care", true},
+		{"car", "", false}, // A prefix of card and care
+		{"ca", "", false},  // Not stored
+		{"cow", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := trie.UniquePrefixOf(tt.word)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("UniquePrefixOf(%q) = %q, %t; want %q, %t", tt.word, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	trie.Delete("dog")
+	if got := trie.TotalPrefixes(); got != 6 {
+		t.Errorf("TotalPrefixes() after deleting dog = %d, want 6", got)
+	}
+	if got, ok := trie.UniquePrefixOf("cat"); got != "cat" || !ok {
+		t.Errorf(`UniquePrefixOf("cat") after deleting dog = %q, %t`, got, ok)
+	}
+	trie.Delete("car")
+	trie.Delete("card")
+	if got, ok := trie.UniquePrefixOf("care"); got != "car" || !ok {
+		t.Errorf(`UniquePrefixOf("care") next to "cat" = %q, %t; want "car", true`, got, ok)
+	}
+	trie.Delete("cat")
+	if got, ok := trie.UniquePrefixOf("care"); got != "c" || !ok {
+		t.Errorf(`UniquePrefixOf("care") as the only word = %q, %t; want "c", true`, got, ok)
+	}
+
+	trie.Delete("care")
+	if got := trie.TotalPrefixes(); got != 0 {
+		t.Errorf("TotalPrefixes() after deleting every word = %d, want 0", got)
+	}
+	empty := NewTrie()
+	if got := empty.TotalPrefixes(); got != 0 || empty.NodeCount()-1 != 0 {
+		t.Errorf("TotalPrefixes() on an empty Trie = %d, NodeCount()-1 = %d; want 0, 0", got, empty.NodeCount()-1)
+	}
+}
+
+func TestTrieGroupByPrefix(t *testing.T) {
+	trie := newTrieWith("a", "ab", "abc", "abd", "b", "bcd", "")
+	want := map[string][]string{
+		"":   {""},
+		"a":  {"a"},
+		"ab": {"ab", "abc", "abd"},
+		"b":  {"b"},
+		"bc": {"bcd"},
+	}
+	groups := trie.GroupByPrefix(2)
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("GroupByPrefix(2) = %q, want %q", groups, want)
+	}
+
+	for _, k := range []int{-1, 0, 1, 3, 10} {
+		var all []string
+		for key, words := range trie.GroupByPrefix(k) {
+			for _, word := range words {
+				if !strings.HasPrefix(word, key) || len(key) != min(max(k, 0), len(word)) {
+					t.Errorf("GroupByPrefix(%d): %q is in bucket %q", k, word, key)
+				}
+			}
+			all = append(all, words...)
+		}
+		slices.Sort(all)
+		if !slices.Equal(all, trie.Words()) {
+			t.Errorf("GroupByPrefix(%d) buckets hold %q, want every word once", k, all)
+		}
+	}
+}
+
+func TestTrieCompact(t *testing.T) {
+	trie := newTrieWith("application", "applicable", "apple", "app", "banana", "band")
+	for _, word := range []string{"application", "applicable", "banana"} {
+		trie.Delete(word)
+	}
+	before := trie.NodeCount()
+
+	// Below "appl" only "e" still leads to a word, so "ica" + "tion" + "ble"
+	// go (10 nodes), and "ana" goes from banana while band keeps "ban".
+	removed := trie.Compact()
+	if after := trie.NodeCount(); after != before-removed || removed == 0 {
+		t.Fatalf("Compact() = %d, NodeCount() went from %d to %d", removed, before, after)
+	}
+	if removed != 13 {
+		t.Errorf("Compact() = %d, want 13", removed)
+	}
+	if err := trie.Validate(); err != nil {
+		t.Errorf("Validate() after Compact = %v", err)
+	}
+	for _, word := range []string{"apple", "app", "band"} {
+		if !trie.Search(word) {
+			t.Errorf("Search(%q) after Compact = false, want true", word)
+		}
+	}
+	if got := trie.Compact(); got != 0 {
+		t.Errorf("second Compact() = %d, want 0", got)
+	}
+}
+
+func TestTrieWordsRoundTrip(t *testing.T) {
+	rng := newTestRand(135)
+	for range 20 {
+		trie := newTrieWith(randomWords(rng, rng.IntN(200), 0, 8, 5)...)
+		for range rng.IntN(20) {
+			trie.Delete(randomWord(rng, 0, 8, 5))
+		}
+		rebuilt := NewTrieFromWords(trie.Words())
+		if !rebuilt.Equal(trie) || !trie.Equal(rebuilt) {
+			t.Fatalf("NewTrieFromWords(t.Words()) is not Equal to t: %q vs %q", rebuilt.Words(), trie.Words())
+		}
+		if err := rebuilt.Validate(); err != nil {
+			t.Fatalf("Validate() on the rebuilt Trie = %v", err)
+		}
+	}
+	if NewTrieFromWords(nil).Equal(newTrieWith("a")) {
+		t.Error("an empty Trie is Equal to one holding \"a\"")
+	}
+}
+
+func TestTrieWordsOfLength(t *testing.T) {
+	trie := newTrieWith("", "a", "an", "at", "ant", "and", "cat", "cart", "carts")
+	trie.Delete("at")
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{0, []string{""}},
+		{1, []string{"a"}},
+		{2, []string{"an"}}, // "at" was deleted
+		{3, []string{"and", "ant", "cat"}},
+		{4, []string{"cart"}},
+		{5, []string{"carts"}},
+		{6, []string{}},
+		{-1, []string{}},
+	}
+	for _, tt := range tests {
+		if got := trie.WordsOfLength(tt.n); !slices.Equal(got, tt.want) || got == nil {
+			t.Errorf("WordsOfLength(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+		if got := trie.CountWordsOfLength(tt.n); got != len(tt.want) {
+			t.Errorf("CountWordsOfLength(%d) = %d, want %d", tt.n, got, len(tt.want))
+		}
+	}
+	if got := NewTrie().WordsOfLength(0); len(got) != 0 {
+		t.Errorf("WordsOfLength(0) on an empty Trie = %q, want []", got)
+	}
+}
+
+func TestTrieLeafCountAndBranchingFactor(t *testing.T) {
+	tests := []struct {
+		name          string
+		words         []string
+		wantLeaves    int
+		wantBranching float64
+	}{
+		// root(c,d) c(a) ca(r,t) d(o) do(g): 7 edges over 5 internal nodes
+		{"car cat dog", []string{"car", "cat", "dog"}, 3, 1.4},
+		{"chain", []string{"abc"}, 1, 1},
+		{"prefix words", []string{"a", "ab", "abc"}, 1, 1},
+		{"fan out", []string{"a", "b", "c", "d"}, 4, 4},
+		{"empty", nil, 1, 0}, // The childless root is a leaf
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trie := newTrieWith(tt.words...)
+			if got := trie.LeafCount(); got != tt.wantLeaves {
+				t.Errorf("LeafCount() = %d, want %d", got, tt.wantLeaves)
+			}
+			if got := trie.AverageBranchingFactor(); got != tt.wantBranching {
+				t.Errorf("AverageBranchingFactor() = %v, want %v", got, tt.wantBranching)
+			}
+		})
+	}
+}
+
+func TestTrieNextCharWeights(t *testing.T) {
+	trie := newTrieWith("cat", "cats", "catalog", "car", "card", "care", "ca", "cow")
+	trie.Insert("cab")
+	trie.Delete("cab") // Soft-deleted branches carry no weight
+	tests := []struct {
+		prefix string
+		want   map[byte]int
+	}{
+		{"ca", map[byte]int{'t': 3, 'r': 3}},
+		{"c", map[byte]int{'a': 7, 'o': 1}},
+		{"", map[byte]int{'c': 8}},
+		{"cat", map[byte]int{'s': 1, 'a': 1}},
+		{"cats", map[byte]int{}},
+		{"dog", map[byte]int{}},
+	}
+	for _, tt := range tests {
+		if got := trie.NextCharWeights(tt.prefix); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("NextCharWeights(%q) = %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestTrieMinUniquePrefixes(t *testing.T) {
+	words := []string{"dog", "deer", "deal", "dove", "zebra", "car", "card", "care"}
+	trie := newTrieWith(words...)
+	want := map[string]string{
+		"dog":   "dog",
+		"dove":  "dov",
+		"deer":  "dee",
+		"deal":  "dea",
+		"zebra": "z",
+		"car":   "car", // A prefix of card and care, so only itself
+		"card":  "card",
+		"care":  "care",
+	}
+	got := trie.MinUniquePrefixes()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MinUniquePrefixes() = %v, want %v", got, want)
+	}
+
+	// Check the defining property directly on a larger random set.
+	words = randomWords(newTestRand(151), 300, 1, 7, 4)
+	trie = newTrieWith(words...)
+	for word, prefix := range trie.MinUniquePrefixes() {
+		if !strings.HasPrefix(word, prefix) {
+			t.Fatalf("prefix %q of %q is not a prefix of it", prefix, word)
+		}
+		shared := len(trie.CollectAllWordsStartingWith(prefix)) > 1
+		if shared != (prefix == word && trie.Lookup(word) == WordAndPrefix) {
+			t.Fatalf("prefix %q of %q is shared = %t", prefix, word, shared)
+		}
+		if len(prefix) > 1 && prefix != word && len(trie.CollectAllWordsStartingWith(prefix[:len(prefix)-1])) == 1 {
+			t.Fatalf("prefix %q of %q is not the shortest unique one", prefix, word)
+		}
+	}
+}
+
+// cancelAfterContext is a context whose Err starts reporting
+// context.Canceled after it has been checked n times.
+type cancelAfterContext struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfterContext) Err() error {
+	if c.n <= 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+func TestTrieCollectWithPrefixCtx(t *testing.T) {
+	trie := newTrieWith(loadWords(t, "words.txt")...)
+	all := trie.CollectAllWordsStartingWith("c")
+
+	t.Run("complete", func(t *testing.T) {
+		got, err := trie.CollectWithPrefixCtx(context.Background(), "c", 0)
+		if err != nil || !slices.Equal(got, all) {
+			t.Errorf("CollectWithPrefixCtx(\"c\", 0) = %d words, %v; want %d words", len(got), err, len(all))
+		}
+		if got, err := trie.CollectWithPrefixCtx(context.Background(), "qqq", 0); err != nil || got == nil || len(got) != 0 {
+			t.Errorf("CollectWithPrefixCtx(\"qqq\", 0) = %q, %v; want [], nil", got, err)
+		}
+	})
+	t.Run("limit", func(t *testing.T) {
+		for _, limit := range []int{1, 5, len(all), len(all) + 10} {
+			got, err := trie.CollectWithPrefixCtx(context.Background(), "c", limit)
+			if want := all[:min(limit, len(all))]; err != nil || !slices.Equal(got, want) {
+				t.Errorf("CollectWithPrefixCtx(\"c\", %d) = %q, %v; want the first %d words", limit, got, err, len(want))
+			}
+		}
+	})
+	t.Run("cancelled before", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if got, err := trie.CollectWithPrefixCtx(ctx, "c", 0); got != nil || !errors.Is(err, context.Canceled) {
+			t.Errorf("CollectWithPrefixCtx(cancelled) = %q, %v; want nil, context.Canceled", got, err)
+		}
+	})
+	t.Run("cancelled midway", func(t *testing.T) {
+		ctx := &cancelAfterContext{Context: context.Background(), n: 20}
+		got, err := trie.CollectWithPrefixCtx(ctx, "c", 0)
+		if got != nil || !errors.Is(err, context.Canceled) {
+			t.Errorf("CollectWithPrefixCtx cancelled midway = %d words, %v; want nil, context.Canceled", len(got), err)
+		}
+	})
+}
+
+// matchesPattern reports whether word matches a '.'-wildcard pattern.
+func matchesPattern(word, pattern string) bool {
+	if len(word) != len(pattern) {
+		return false
+	}
+	for i := range len(word) {
+		if pattern[i] != '.' && pattern[i] != word[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTrieCountPatternMatches(t *testing.T) {
+	rng := newTestRand(166)
+	trie := newTrieWith(randomWords(rng, 400, 0, 5, 3)...)
+	trie.Insert("abc")
+	trie.Insert("abc") // Counted once
+	trie.Insert("abd")
+	trie.Delete("abd") // Not counted at all
+	patterns := []string{"", ".", "..", "...", "a.c", "ab.", "....", ".....", "......", "zzz", "c.b.a"}
+	for _, word := range randomWords(rng, 100, 0, 5, 3) {
+		b := []byte(word)
+		for i := range b {
+			if rng.IntN(2) == 0 {
+				b[i] = '.'
+			}
+		}
+		patterns = append(patterns, string(b))
+	}
+
+	for _, pattern := range patterns {
+		var matched []string
+		for _, word := range trie.Words() {
+			if matchesPattern(word, pattern) {
+				matched = append(matched, word)
+			}
+		}
+		if got := trie.CountPatternMatches(pattern); got != len(matched) {
+			t.Errorf("CountPatternMatches(%q) = %d, want %d (%q)", pattern, got, len(matched), matched)
+		}
+		if got := trie.SearchPattern(pattern); got != (len(matched) > 0) {
+			t.Errorf("SearchPattern(%q) = %t, want %t", pattern, got, len(matched) > 0)
+		}
+	}
+}
+
+func TestTrieStatsCounters(t *testing.T) {
+	trie := NewTrie()
+	steps := []struct {
+		name string
+		op   func()
+		want TrieStats // Counters and Words only
+	}{
+		{"new", func() {}, TrieStats{}},
+		{"insert", func() { trie.Insert("cat") }, TrieStats{Inserts: 1, Words: 1}},
+		{"insert repeat", func() { trie.Insert("cat") }, TrieStats{Inserts: 2, Words: 1}},
+		{"insert more", func() { trie.InsertAll([]string{"car", "card", "dog"}) }, TrieStats{Inserts: 5, Words: 4}},
+		{"search hit", func() { trie.Search("cat") }, TrieStats{Inserts: 5, Searches: 1, Words: 4}},
+		{"search miss", func() { trie.Search("cow") }, TrieStats{Inserts: 5, Searches: 2, Words: 4}},
+		{"delete", func() { trie.Delete("cat") }, TrieStats{Inserts: 5, Deletes: 1, Searches: 2, Words: 3}},
+		{"delete missing", func() { trie.Delete("cat") }, TrieStats{Inserts: 5, Deletes: 1, Searches: 2, Words: 3}},
+		{"hard delete", func() { trie.HardDelete("dog") }, TrieStats{Inserts: 5, Deletes: 2, Searches: 2, Words: 2}},
+		{"delete prefix", func() { trie.DeletePrefix("car") }, TrieStats{Inserts: 5, Deletes: 4, Searches: 2, Words: 0}},
+		{"refused insert", func() { trie.InsertE("Dog") }, TrieStats{Inserts: 5, Deletes: 4, Searches: 2, Words: 0}},
+		{"restore", func() { trie.Restore(newTrieWith("a", "b").Checkpoint()) }, TrieStats{Inserts: 5, Deletes: 4, Searches: 2, Words: 2}},
+	}
+	for _, step := range steps {
+		step.op()
+		got := trie.Stats()
+		got.Nodes, got.MaxDepth, got.AvgBranching = 0, 0, 0
+		if got != step.want {
+			t.Errorf("after %s: Stats() = %+v, want %+v", step.name, got, step.want)
+		}
+	}
+
+	stats := newTrieWith("car", "card", "dog").Stats()
+	if stats.Nodes != 8 || stats.MaxDepth != 4 || stats.AvgBranching != 7.0/6 {
+		t.Errorf("Stats() shape = %d nodes, depth %d, branching %v; want 8, 4, %v", stats.Nodes, stats.MaxDepth, stats.AvgBranching, 7.0/6)
+	}
+}
+
+func TestTrieAnyAndAllStartWith(t *testing.T) {
+	trie := newTrieWith("apple", "banana", "cherry")
+	trie.Insert("durian")
+	trie.Delete("durian") // Its path is left behind but matches nothing
+	tests := []struct {
+		name     string
+		prefixes []string
+		any, all bool
+	}{
+		{"empty list", nil, false, true},
+		{"all match", []string{"ap", "b", "cherry"}, true, true},
+		{"first matches", []string{"a", "x", "y"}, true, false},
+		{"last matches", []string{"x", "y", "ch"}, true, false},
+		{"none match", []string{"x", "applez", "du"}, false, false},
+		{"empty prefix", []string{""}, true, true},
+		{"invalid only", []string{"Ap", "b4"}, false, false},
+		{"invalid then valid", []string{"B!", "ban"}, true, false},
+		{"valid then invalid", []string{"ban", "B!"}, true, false},
+	}
+	for _, tt := range tests {
+		if got := trie.AnyStartsWith(tt.prefixes); got != tt.any {
+			t.Errorf("%s: AnyStartsWith(%q) = %t, want %t", tt.name, tt.prefixes, got, tt.any)
+		}
+		if got := trie.AllStartWith(tt.prefixes); got != tt.all {
+			t.Errorf("%s: AllStartWith(%q) = %t, want %t", tt.name, tt.prefixes, got, tt.all)
+		}
+	}
+	if NewTrie().AnyStartsWith([]string{""}) {
+		t.Error(`AnyStartsWith([""]) on an empty Trie = true, want false`)
+	}
+}
+
+func TestTrieErrorVariantsReportTypedError(t *testing.T) {
+	trie := newTrieWith("apple")
+	calls := map[string]func(s string) error{
+		"InsertE":     trie.InsertE,
+		"SearchE":     func(s string) error { _, err := trie.SearchE(s); return err },
+		"StartsWithE": func(s string) error { _, err := trie.StartsWithE(s); return err },
+		"DeleteE":     func(s string) error { _, err := trie.DeleteE(s); return err },
+	}
+	for name, call := range calls {
+		for _, tt := range []struct {
+			s    string
+			char byte
+			pos  int
+		}{
+			{"Apple", 'A', 0},
+			{"apple pie", ' ', 5},
+			{"it's.", '.', 4},
+			{"über", 0xc3, 0},
+		} {
+			var ice InvalidCharError
+			if err := call(tt.s); !errors.As(err, &ice) || ice.Char != tt.char || ice.Pos != tt.pos {
+				t.Errorf("%s(%q) = %v, want InvalidCharError{%q, %d}", name, tt.s, err, tt.char, tt.pos)
+			}
+		}
+		if err := call("apple"); err != nil {
+			t.Errorf("%s(\"apple\") = %v, want nil", name, err)
+		}
+	}
+	if got := (InvalidCharError{Char: '!', Pos: 3}).Error(); !strings.Contains(got, `'!' at position 3`) {
+		t.Errorf("Error() = %q, want it to name the character and position", got)
+	}
+}
+
+func TestTrieHardDelete(t *testing.T) {
+	tests := []struct {
+		name       string
+		words      []string
+		delete     string
+		wantFound  bool
+		wantPruned bool
+		wantNodes  int // Including the root
+		remaining  []string
+	}{
+		{"only word", []string{"cat"}, "cat", true, true, 1, []string{}},
+		{"leaf beside a sibling", []string{"cat", "car"}, "cat", true, true, 4, []string{"car"}},
+		{"word that is a prefix", []string{"app", "apple"}, "app", true, false, 6, []string{"apple"}},
+		{"word with a stored prefix", []string{"app", "apple"}, "apple", true, true, 4, []string{"app"}},
+		{"branch off a longer word", []string{"car", "carton", "cart"}, "carton", true, true, 5, []string{"car", "cart"}},
+		{"prefix only", []string{"apple"}, "app", false, false, 6, []string{"apple"}},
+		{"nonexistent", []string{"apple"}, "banana", false, false, 6, []string{"apple"}},
+		{"empty string", []string{"", "a"}, "", true, false, 2, []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trie := newTrieWith(tt.words...)
+			found, pruned := trie.HardDelete(tt.delete)
+			if found != tt.wantFound || pruned != tt.wantPruned {
+				t.Errorf("HardDelete(%q) = (%t, %t), want (%t, %t)", tt.delete, found, pruned, tt.wantFound, tt.wantPruned)
+			}
+			checkTrieInvariant(t, trie)
+			if got := trie.NodeCount(); got != tt.wantNodes {
+				t.Errorf("NodeCount() = %d, want %d", got, tt.wantNodes)
+			}
+			if got := trie.Words(); !slices.Equal(got, tt.remaining) {
+				t.Errorf("Words() after HardDelete(%q) = %q, want %q", tt.delete, got, tt.remaining)
+			}
+		})
+	}
+}
+
+// TestTrieHardDeleteShrinksToRoot deletes a whole dictionary and checks that
+// the node count falls with every deletion and ends at the bare root.
+func TestTrieHardDeleteShrinksToRoot(t *testing.T) {
+	words := randomWords(newTestRand(254), 500, 1, 8, 5)
+	trie := newTrieWith(words...)
+	nodes := trie.NodeCount()
+	for _, word := range trie.Words() {
+		trie.HardDelete(word)
+		if got := trie.NodeCount(); got > nodes {
+			t.Fatalf("NodeCount() grew from %d to %d after HardDelete(%q)", nodes, got, word)
+		}
+		nodes = trie.NodeCount()
+	}
+	if nodes != 1 {
+		t.Errorf("NodeCount() after deleting every word = %d, want 1", nodes)
+	}
+}
+
+func TestTrieCountWordsAndNodeCount(t *testing.T) {
+	trie := NewTrie()
+	if trie.CountWords() != 0 || trie.NodeCount() != 1 {
+		t.Fatalf("empty Trie: CountWords() = %d, NodeCount() = %d, want 0 and 1", trie.CountWords(), trie.NodeCount())
+	}
+
+	// Nodes: root, c-a-t, r of "car", t of "cart", d-o-g = 1 + 3 + 1 + 1 + 3
+	for _, word := range []string{"cat", "car", "cart", "dog", "cat"} {
+		trie.Insert(word)
+	}
+	if got := trie.CountWords(); got != 4 {
+		t.Errorf("CountWords() = %d, want 4", got)
+	}
+	if got := trie.NodeCount(); got != 9 {
+		t.Errorf("NodeCount() = %d, want 9", got)
+	}
+
+	// Soft deletes stop the words counting but keep their nodes allocated.
+	trie.Delete("car")
+	trie.Delete("dog")
+	trie.Delete("dog")
+	if got := trie.CountWords(); got != 2 {
+		t.Errorf("CountWords() after soft deletes = %d, want 2", got)
+	}
+	if got := trie.NodeCount(); got != 9 {
+		t.Errorf("NodeCount() after soft deletes = %d, want 9", got)
+	}
+	if got := trie.CountWords(); got != len(trie.Words()) {
+		t.Errorf("CountWords() = %d, Words() has %d", got, len(trie.Words()))
+	}
+}
+
+func TestTriePrefixCount(t *testing.T) {
+	trie := newTrieWith("apple", "app", "apply", "ape", "banana", "apple", "apple")
+
+	tests := []struct {
+		prefix string
+		want   int
+	}{
+		{"", 5},
+		{"a", 4},
+		{"ap", 4},
+		{"app", 3},
+		{"apple", 1},
+		{"apples", 0},
+		{"b", 1},
+		{"c", 0},
+	}
+	for _, tt := range tests {
+		if got := trie.PrefixCount(tt.prefix); got != tt.want {
+			t.Errorf("PrefixCount(%q) = %d, want %d (duplicates count once)", tt.prefix, got, tt.want)
+		}
+	}
+
+	trie.Delete("apple")
+	if got := trie.PrefixCount("app"); got != 2 {
+		t.Errorf(`PrefixCount("app") after Delete("apple") = %d, want 2`, got)
+	}
+	trie.Delete("apple")
+	if got := trie.PrefixCount("app"); got != 2 {
+		t.Errorf(`PrefixCount("app") after deleting "apple" twice = %d, want 2`, got)
+	}
+	trie.HardDelete("apply")
+	if got := trie.PrefixCount("app"); got != 1 {
+		t.Errorf(`PrefixCount("app") after HardDelete("apply") = %d, want 1`, got)
+	}
+	trie.HardDelete("app")
+	if got := trie.PrefixCount("ap"); got != 1 {
+		t.Errorf(`PrefixCount("ap") after HardDelete("app") = %d, want 1`, got)
+	}
+	trie.Insert("apple")
+	if got := trie.PrefixCount("a"); got != 2 {
+		t.Errorf(`PrefixCount("a") after re-inserting "apple" = %d, want 2`, got)
+	}
+}
+
+func TestTrieSearchPattern(t *testing.T) {
+	trie := newTrieWith("cat", "cut", "cart", "dog", "a")
+	trie.Delete("cut")
+
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"c.t", true},
+		{"cat", true},
+		{"c.r", false},   // "car" is only a prefix of "cart"
+		{"ca", false},    // Pure prefix
+		{"c..", true},    // "cat"
+		{"c..t", true},   // "cart"
+		{"cu.", false},   // "cut" was deleted
+		{"...", true},    // Any three-letter word
+		{"....", true},   // "cart"
+		{".....", false}, // Longer than any stored word
+		{"cart.", false}, // Longer than the word it extends
+		{".at", true},    // Leading dot
+		{".og", true},    // Leading dot
+		{".ut", false},   // Leading dot over a deleted word
+		{".", true},      // "a"
+		{"", false},      // The empty string isn't stored
+		{"c-t", false},   // Characters outside the alphabet match nothing
+	}
+	for _, tt := range tests {
+		if got := trie.SearchPattern(tt.pattern); got != tt.want {
+			t.Errorf("SearchPattern(%q) = %t, want %t", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestTrieSuggest(t *testing.T) {
+	trie := NewTrie()
+	for word, times := range map[string]int{"car": 3, "cat": 5, "cart": 3, "care": 1, "cab": 3, "dog": 9} {
+		for range times {
+			trie.Insert(word)
+		}
+	}
+
+	tests := []struct {
+		prefix string
+		limit  int
+		want   []string
+	}{
+		{"ca", 10, []string{"cat", "cab", "car", "cart", "care"}},
+		{"ca", 3, []string{"cat", "cab", "car"}},
+		{"car", 2, []string{"car", "cart"}},
+		{"", 2, []string{"dog", "cat"}},
+		{"x", 5, []string{}},
+		{"ca", 0, []string{}},
+		{"ca", -1, []string{}},
+	}
+	for _, tt := range tests {
+		got := trie.Suggest(tt.prefix, tt.limit)
+		if got == nil || !slices.Equal(got, tt.want) {
+			t.Errorf("Suggest(%q, %d) = %#v, want %q", tt.prefix, tt.limit, got, tt.want)
+		}
+	}
+
+	trie.Insert("care")
+	trie.Insert("care")
+	trie.Insert("care")
+	if got, want := trie.Suggest("car", 3), []string{"care", "car", "cart"}; !slices.Equal(got, want) {
+		t.Errorf(`Suggest("car", 3) after more inserts of "care" = %q, want %q`, got, want)
+	}
+}
+
+func TestTrieLongestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		words  []string
+		delete []string
+		want   string
+	}{
+		{"empty trie", nil, nil, ""},
+		{"single word", []string{"flower"}, nil, "flower"},
+		{"branching", []string{"flower", "flow", "flight"}, nil, "fl"},
+		{"word ends the prefix", []string{"flow", "flower"}, nil, "flow"},
+		{"nothing shared", []string{"dog", "racecar", "car"}, nil, ""},
+		{"empty string stored", []string{"", "abc"}, nil, ""},
+		{"soft-deleted branch ignored", []string{"flower", "flight"}, []string{"flight"}, "flower"},
+		{"everything deleted", []string{"flower"}, []string{"flower"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trie := newTrieWith(tt.words...)
+			for _, word := range tt.delete {
+				trie.Delete(word)
+			}
+			if got := trie.LongestCommonPrefix(); got != tt.want {
+				t.Errorf("LongestCommonPrefix() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrieLongestPrefixOf(t *testing.T) {
+	trie := newTrieWith("a", "ap", "app", "bat")
+
+	tests := []struct {
+		text   string
+		want   string
+		wantOK bool
+	}{
+		{"apple", "app", true},
+		{"app", "app", true}, // text itself is stored
+		{"apricot", "ap", true},
+		{"a", "a", true},
+		{"banana", "", false},
+		{"ba", "", false}, // Only a prefix of "bat"
+		{"batch", "bat", true},
+		{"", "", false},
+		{"zzz", "", false},
+		{"app?le", "app", true}, // Stops at the first character outside the alphabet
+	}
+	for _, tt := range tests {
+		if got, ok := trie.LongestPrefixOf(tt.text); got != tt.want || ok != tt.wantOK {
+			t.Errorf("LongestPrefixOf(%q) = %q, %t, want %q, %t", tt.text, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	trie.Insert("")
+	if got, ok := trie.LongestPrefixOf("zzz"); got != "" || !ok {
+		t.Errorf(`LongestPrefixOf("zzz") with "" stored = %q, %t, want "", true`, got, ok)
+	}
+}
+
+func TestTrieSearchFuzzy(t *testing.T) {
+	trie := newTrieWith("cat", "car", "cart", "at", "scat", "cr", "dog")
+	trie.Delete("cr")
+
+	tests := []struct {
+		word    string
+		maxDist int
+		want    []string
+	}{
+		{"caat", 1, []string{"cart", "cat"}}, // "cart" by one substitution
+		{"caat", 2, []string{"at", "car", "cart", "cat", "scat"}},
+		{"cr", 1, []string{"car"}}, // "cr" itself was deleted
+		{"cr", 2, []string{"at", "car", "cart", "cat"}},
+		{"cat", 0, []string{"cat"}},
+		{"caat", 0, []string{}},
+		{"cr", 0, []string{}},
+		{"cat", -1, []string{}},
+		{"xyzzy", 2, []string{}},
+	}
+	for _, tt := range tests {
+		got := trie.SearchFuzzy(tt.word, tt.maxDist)
+		if got == nil || !slices.Equal(got, tt.want) {
+			t.Errorf("SearchFuzzy(%q, %d) = %#v, want %q", tt.word, tt.maxDist, got, tt.want)
+		}
+	}
+}
+
+func TestTrieIterate(t *testing.T) {
+	trie := newTrieWith(loadWords(t, "words.txt")...)
+	trie.Delete("apple")
+	want := trie.Words()
+
+	var got []string
+	trie.Iterate(func(word string) bool {
+		got = append(got, word)
+		return true
+	})
+	if !slices.Equal(got, want) {
+		t.Fatalf("Iterate visited %d words, want the %d of Words() in order", len(got), len(want))
+	}
+
+	for _, n := range []int{1, 5, len(want) - 1} {
+		calls := 0
+		trie.Iterate(func(word string) bool {
+			if word != want[calls] {
+				t.Fatalf("call %d got %q, want %q", calls, word, want[calls])
+			}
+			calls++
+			return calls < n
+		})
+		if calls != n {
+			t.Errorf("Iterate stopping after %d words made %d calls", n, calls)
+		}
+	}
+
+	// range-over-func panics if the iterator keeps calling after a break.
+	var first []string
+	for word := range trie.Iterate {
+		if len(first) == 3 {
+			break
+		}
+		first = append(first, word)
+	}
+	if !slices.Equal(first, want[:3]) {
+		t.Errorf("range over Iterate = %q, want %q", first, want[:3])
+	}
+
+	NewTrie().Iterate(func(word string) bool {
+		t.Errorf("Iterate on an empty Trie called fn(%q)", word)
+		return true
+	})
+}
+
+func TestTrieInsertAll(t *testing.T) {
+	trie := NewTrie()
+	tests := []struct {
+		words []string
+		want  int
+	}{
+		{[]string{"cat", "car", "cat", "cat"}, 2}, // Duplicates within the input count once
+		{[]string{"car", "card", "care"}, 2},      // Overlaps what is stored
+		{[]string{"ca", "c"}, 2},                  // Prefixes of stored words are new words
+		{[]string{"cat", "card"}, 0},
+		{[]string{}, 0},
+		{nil, 0},
+	}
+	total := 0
+	for _, tt := range tests {
+		if got := trie.InsertAll(tt.words); got != tt.want {
+			t.Errorf("InsertAll(%q) = %d, want %d", tt.words, got, tt.want)
+		}
+		total += tt.want
+		if got := trie.CountWords(); got != total {
+			t.Errorf("CountWords() after InsertAll(%q) = %d, want %d", tt.words, got, total)
+		}
+	}
+	if got := trie.Count("cat"); got != 4 {
+		t.Errorf(`Count("cat") = %d, want 4 (every insert counts)`, got)
+	}
+
+	trie.Delete("car")
+	if got := trie.InsertAll([]string{"car", "car"}); got != 1 {
+		t.Errorf(`InsertAll after Delete("car") = %d, want 1 (a soft-deleted word is new again)`, got)
+	}
+}
+
+func TestTrieDeletePrefix(t *testing.T) {
+	words := []string{"car", "card", "care", "cart", "cat", "dog", "ca"}
+	tests := []struct {
+		prefix    string
+		want      int
+		remaining []string
+		nodes     int // Including the root
+	}{
+		{"car", 4, []string{"ca", "cat", "dog"}, 7},
+		{"ca", 6, []string{"dog"}, 4},
+		{"c", 6, []string{"dog"}, 4},
+		{"card", 1, []string{"ca", "car", "care", "cart", "cat", "dog"}, 10},
+		{"cards", 0, words, 11},
+		{"x", 0, words, 11},
+		{"", 7, []string{}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			trie := newTrieWith(words...)
+			if got := trie.DeletePrefix(tt.prefix); got != tt.want {
+				t.Errorf("DeletePrefix(%q) = %d, want %d", tt.prefix, got, tt.want)
+			}
+			checkTrieInvariant(t, trie)
+			want := slices.Sorted(slices.Values(tt.remaining))
+			if got := trie.Words(); !slices.Equal(got, want) {
+				t.Errorf("Words() after DeletePrefix(%q) = %q, want %q", tt.prefix, got, want)
+			}
+			if got := trie.NodeCount(); got != tt.nodes {
+				t.Errorf("NodeCount() after DeletePrefix(%q) = %d, want %d (the subtree is unlinked)", tt.prefix, got, tt.nodes)
+			}
+			if tt.prefix != "" && trie.StartsWith(tt.prefix) {
+				t.Errorf("StartsWith(%q) after DeletePrefix = true, want false", tt.prefix)
+			}
+		})
+	}
+
+	trie := newTrieWith("cart", "cat")
+	trie.Delete("cart")
+	nodes := trie.NodeCount()
+	if got := trie.DeletePrefix("car"); got != 0 {
+		t.Errorf(`DeletePrefix("car") over a soft-deleted word = %d, want 0`, got)
+	}
+	if got := trie.NodeCount(); got != nodes {
+		t.Errorf(`NodeCount() after DeletePrefix("car") removed nothing = %d, want %d (untouched)`, got, nodes)
+	}
+}
+
+func TestTrieShortestUniquePrefixes(t *testing.T) {
+	trie := newTrieWith("zebra", "dog", "duck", "dove", "do")
+	trie.Delete("do")
+	want := map[string]string{
+		"zebra": "z",
+		"dog":   "dog", // Shares "do" with "dove", so it takes the whole word
+		"dove":  "dov",
+		"duck":  "du",
+	}
+	got := trie.ShortestUniquePrefixes()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ShortestUniquePrefixes() = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(got, trie.MinUniquePrefixes()) {
+		t.Error("ShortestUniquePrefixes() differs from MinUniquePrefixes()")
+	}
+	if got := NewTrie().ShortestUniquePrefixes(); len(got) != 0 {
+		t.Errorf("ShortestUniquePrefixes() on an empty Trie = %v, want an empty map", got)
+	}
+}